@@ -2,18 +2,56 @@ package animation
 
 import (
 	"fmt"
+	"image/color"
 	"log"
+	"math/rand"
+	"sort"
+	"strings"
 
 	"golang.org/x/mobile/f32"
 	"golang.org/x/mobile/geom"
 	"golang.org/x/mobile/sprite"
 	"golang.org/x/mobile/sprite/clock"
+
+	"github.com/crawshaw/balloon/physics"
+	"github.com/crawshaw/balloon/tint"
 )
 
+// State is a single state of an Animation.
+//
+// Transforms are keyed by the path of the node they apply to, relative to
+// the root node of the Animation. See Lookup for the path syntax.
+//
+// When Duration has passed, the state follows one of its automatic Edges,
+// or if none is open, Next. An edge with a Trigger is only followed when
+// the Animation is triggered. When several edges are open, one is chosen
+// at random by Weight.
+//
+// OnEnter and OnExit, if set, are called by Transition when the
+// Animation enters and leaves the state.
 type State struct {
 	Duration   int
 	Next       string
-	Transforms map[*sprite.Node]Transform
+	Edges      []Edge
+	Transforms map[string]Transform
+
+	OnEnter func(a *Animation, t clock.Time)
+	OnExit  func(a *Animation, t clock.Time)
+}
+
+// Edge is a transition out of a State.
+type Edge struct {
+	Next    string
+	Trigger string                                // if set, the edge is only followed by Trigger
+	If      func(a *Animation, t clock.Time) bool // optional guard, the edge is open if it returns true
+	Weight  int                                   // relative chance of being chosen, 0 means 1
+}
+
+func (e *Edge) weight() int {
+	if e.Weight == 0 {
+		return 1
+	}
+	return e.Weight
 }
 
 // Animation is a state machine for a node tree.
@@ -24,93 +62,586 @@ type State struct {
 //
 // States can transition automatically after some predefined duration, or
 // by calling the Transition method.
+//
+// Layers are further state machines that run concurrently with States.
+// When several machines transform the same node, the transforms are
+// applied in order: first that of States, then those of each layer in
+// the order of Layers.
+//
+// The States map holds no references to nodes, so one map can be shared by
+// several Animations controlling identical node trees.
 type Animation struct {
 	Current string
 	States  map[string]State
+	Layers  []*Layer
+
+	// Crossfade, if non-zero, is the time taken to blend a node from
+	// where an interrupted state left it into the next state. Without it,
+	// an interrupted state jumps to its final position.
+	Crossfade clock.Time
+
+	// Rand is the source for choosing between weighted edges.
+	// If nil, the default source in math/rand is used.
+	Rand *rand.Rand
+
+	// Err is the first error encountered arranging the Animation.
+	// Once Err is set, the Animation stops.
+	Err error
 
 	root           *sprite.Node
+	arrangements   map[string]*Arrangement // path -> arrangement, resolved by init
+	lastTransition clock.Time
+}
+
+// Layer is a state machine that runs alongside the States of an Animation.
+type Layer struct {
+	Name    string
+	Current string
+	States  map[string]State
+
 	lastTransition clock.Time
 }
 
+// machine is one of the state machines of an Animation.
+type machine struct {
+	layer   string // empty for the main States
+	slot    int    // Arrangement transform slot
+	current *string
+	states  map[string]State
+	last    *clock.Time
+}
+
+func (a *Animation) machines() []machine {
+	m := []machine{{
+		current: &a.Current,
+		states:  a.States,
+		last:    &a.lastTransition,
+	}}
+	for i, l := range a.Layers {
+		m = append(m, machine{
+			layer:   l.Name,
+			slot:    i + 1,
+			current: &l.Current,
+			states:  l.States,
+			last:    &l.lastTransition,
+		})
+	}
+	return m
+}
+
+// state describes a state in error messages.
+func (m *machine) state(name string) string {
+	if m.layer == "" {
+		return fmt.Sprintf("state %q", name)
+	}
+	return fmt.Sprintf("layer %q state %q", m.layer, name)
+}
+
 func (a *Animation) Arrange(e sprite.Engine, n *sprite.Node, t clock.Time) {
+	if a.Err != nil {
+		return
+	}
 	if a.root == nil {
-		a.init(n)
+		if err := a.init(n); err != nil {
+			a.fail(err)
+			return
+		}
 	}
 	if a.root != n {
-		// TODO: return error
-		log.Printf("animation.Animation: root node changed (%p, %p)", a.root, n)
+		a.fail(fmt.Errorf("animation.Animation: root node changed (%p, %p)", a.root, n))
 		return
 	}
-	s := a.States[a.Current]
-	if s.Next != "" && clock.Time(s.Duration) < t-a.lastTransition {
-		a.Transition(t, s.Next)
+	for _, m := range a.machines() {
+		s := m.states[*m.current]
+		if clock.Time(s.Duration) >= t-*m.last {
+			continue
+		}
+		next := a.choose(&m, t, "")
+		if next == "" {
+			next = s.Next
+		}
+		if next != "" {
+			a.transition(&m, t, next)
+		}
+	}
+}
+
+// Trigger follows the edges named trigger out of the current states of
+// the main States and each layer. It reports whether any were followed.
+func (a *Animation) Trigger(t clock.Time, trigger string) bool {
+	followed := false
+	for _, m := range a.machines() {
+		if next := a.choose(&m, t, trigger); next != "" {
+			a.transition(&m, t, next)
+			followed = true
+		}
+	}
+	return followed
+}
+
+// choose returns the next state along an open edge with the given
+// trigger out of the current state of m, or "" if there is none.
+func (a *Animation) choose(m *machine, t clock.Time, trigger string) string {
+	var open []*Edge
+	total := 0
+	edges := m.states[*m.current].Edges
+	for i := range edges {
+		e := &edges[i]
+		if e.Trigger != trigger || (e.If != nil && !e.If(a, t)) {
+			continue
+		}
+		open = append(open, e)
+		total += e.weight()
+	}
+	if len(open) == 0 {
+		return ""
+	}
+	var n int
+	if a.Rand != nil {
+		n = a.Rand.Intn(total)
+	} else {
+		n = rand.Intn(total)
+	}
+	for _, e := range open {
+		if n -= e.weight(); n < 0 {
+			return e.Next
+		}
 	}
+	panic("unreachable")
 }
 
+// Transition moves the Animation to the named state at time t.
 func (a *Animation) Transition(t clock.Time, name string) {
-	log.Printf("animation: Transition from %q to %q", a.Current, name)
-	for n, transform := range a.States[a.Current].Transforms {
+	m := a.machines()[0]
+	a.transition(&m, t, name)
+}
+
+// TransitionLayer moves the named layer to the named state at time t.
+func (a *Animation) TransitionLayer(t clock.Time, layer, name string) {
+	for _, m := range a.machines()[1:] {
+		if m.layer == layer {
+			a.transition(&m, t, name)
+			return
+		}
+	}
+	a.fail(fmt.Errorf("animation.Animation: transition in non-existent layer %q", layer))
+}
+
+// Layer returns the named layer, or nil if there is none.
+func (a *Animation) Layer(name string) *Layer {
+	for _, l := range a.Layers {
+		if l.Name == name {
+			return l
+		}
+	}
+	return nil
+}
+
+func (a *Animation) transition(m *machine, t clock.Time, name string) {
+	log.Printf("animation: Transition from %q to %q", *m.current, name)
+	if _, exists := m.states[name]; !exists {
+		a.fail(fmt.Errorf("animation.Animation: transition to non-existent %s", m.state(name)))
+		return
+	}
+	old := m.states[*m.current]
+	for path, transform := range old.Transforms {
+		ar := a.arrangements[path]
+		if ar == nil {
+			continue
+		}
+		interrupted := a.Crossfade > 0 && t < ar.transform(m.slot).T1
+		var cur Arrangement
+		if interrupted {
+			cur = ar.pose(t)
+		}
 		// Squash the final animation state down onto the node.
-		ar := n.Arranger.(*Arrangement)
-		ar.Transform = Transform{}
+		ar.setTransform(m.slot, layerTransform{})
 		transform.Transformer.Transform(ar, 1)
+		if interrupted {
+			ar.blendFrom(&cur, t, t+a.Crossfade)
+		}
+	}
+	if old.OnExit != nil {
+		old.OnExit(a, t)
+	}
+	*m.current = name
+	*m.last = t
+	if a.root != nil {
+		// Before the root node is known, paths cannot be resolved.
+		// The transforms of the new state are started by init.
+		a.start(m)
+	}
+	if s := m.states[name]; s.OnEnter != nil {
+		s.OnEnter(a, t)
+	}
+}
+
+// Validate reports the first problem found in the state machines: a
+// missing initial or next state, a cycle of zero-duration states, or
+// a layer without a unique name. Once the Animation has been arranged,
+// it also reports paths that do not name a node arranged by an
+// *Arrangement.
+func (a *Animation) Validate() error {
+	layers := make(map[string]bool)
+	for _, l := range a.Layers {
+		if l.Name == "" || layers[l.Name] {
+			return fmt.Errorf("animation.Animation: layer name %q is not unique", l.Name)
+		}
+		layers[l.Name] = true
+	}
+	for _, m := range a.machines() {
+		if err := a.validate(&m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *Animation) validate(m *machine) error {
+	names := make([]string, 0, len(m.states))
+	for name := range m.states {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if _, exists := m.states[*m.current]; !exists && *m.current != "" {
+		return fmt.Errorf("animation.Animation: current %s does not exist", m.state(*m.current))
+	}
+	for _, name := range names {
+		s := m.states[name]
+		if s.Next != "" {
+			if _, exists := m.states[s.Next]; !exists {
+				return fmt.Errorf("animation.Animation: %s transitions to non-existent state %q", m.state(name), s.Next)
+			}
+		}
+		for _, e := range s.Edges {
+			if _, exists := m.states[e.Next]; !exists {
+				return fmt.Errorf("animation.Animation: %s has edge to non-existent state %q", m.state(name), e.Next)
+			}
+			if e.Weight < 0 {
+				return fmt.Errorf("animation.Animation: %s has edge to %q with negative weight", m.state(name), e.Next)
+			}
+		}
+		if a.root == nil {
+			continue
+		}
+		paths := make([]string, 0, len(s.Transforms))
+		for path := range s.Transforms {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		for _, path := range paths {
+			n := Lookup(a.root, path)
+			if n == nil {
+				return fmt.Errorf("animation.Animation: %s refers to non-existent node %q", m.state(name), path)
+			}
+			if _, ok := n.Arranger.(*Arrangement); !ok {
+				return fmt.Errorf("animation.Animation: %s: node %q has Arranger %T, want *animation.Arrangement", m.state(name), path, n.Arranger)
+			}
+		}
+	}
+	for _, name := range names {
+		if cycle := zeroCycle(m.states, name); cycle != nil {
+			return fmt.Errorf("animation.Animation: zero-duration cycle %s", strings.Join(cycle, " -> "))
+		}
+	}
+	return nil
+}
+
+// zeroCycle returns the states of a cycle of zero-duration transitions
+// from name back to name, or nil if there is none.
+//
+// Transitions are Next and the automatic Edges. A guarded edge may be
+// open, so it is followed, and Next is followed unless an unguarded
+// automatic edge is always taken instead.
+func zeroCycle(states map[string]State, name string) []string {
+	seen := make(map[string]bool)
+	var visit func(path []string) []string
+	visit = func(path []string) []string {
+		s := states[path[len(path)-1]]
+		if s.Duration != 0 {
+			return nil
+		}
+		for _, next := range automatic(s) {
+			p := append(path[:len(path):len(path)], next)
+			if next == name {
+				return p
+			}
+			if seen[next] {
+				continue
+			}
+			seen[next] = true
+			if cycle := visit(p); cycle != nil {
+				return cycle
+			}
+		}
+		return nil
 	}
-	s := a.States[name]
-	for n, transform := range s.Transforms {
-		ar := n.Arranger.(*Arrangement)
-		ar.T0 = t
-		ar.T1 = t + clock.Time(s.Duration)
-		ar.Transform = transform
+	return visit([]string{name})
+}
+
+// automatic returns the states s may transition to without a trigger.
+func automatic(s State) []string {
+	var next []string
+	always := false
+	for _, e := range s.Edges {
+		if e.Trigger != "" {
+			continue
+		}
+		next = append(next, e.Next)
+		if e.If == nil {
+			always = true
+		}
+	}
+	if s.Next != "" && !always {
+		next = append(next, s.Next)
+	}
+	return next
+}
+
+func (a *Animation) fail(err error) {
+	log.Print(err)
+	a.Err = err
+}
+
+// start assigns the transforms of the current state of m to their nodes.
+func (a *Animation) start(m *machine) {
+	s := m.states[*m.current]
+	for path, transform := range s.Transforms {
+		ar := a.arrangements[path]
+		if ar == nil {
+			continue
+		}
+		ar.setTransform(m.slot, layerTransform{
+			T0:        *m.last,
+			T1:        *m.last + clock.Time(s.Duration),
+			Transform: transform,
+		})
 	}
-	a.Current = name
-	a.lastTransition = t
 }
 
 func (a *Animation) init(root *sprite.Node) error {
 	a.root = root
-	for stateName, s := range a.States {
-		if s.Next != "" {
-			if _, exists := a.States[s.Next]; !exists {
-				return fmt.Errorf("animation.Animation: state %q transitions to non-existent state %q", stateName, s.Next)
+	if err := a.Validate(); err != nil {
+		return err
+	}
+	a.arrangements = make(map[string]*Arrangement)
+	machines := a.machines()
+	for _, m := range machines {
+		for _, s := range m.states {
+			for path := range s.Transforms {
+				a.arrangements[path] = Lookup(root, path).Arranger.(*Arrangement)
 			}
 		}
 	}
+	for _, m := range machines {
+		a.start(&m)
+	}
 	return nil
 }
 
 // Arrangement is a sprite Arranger that uses high-level concepts to
 // transform a sprite Node.
+//
+// Place, if set, maps the unit square of the node to the part of it
+// where SubTex is drawn, as for a trimmed frame of a sprite sheet (see
+// atlas.Frame.Place). Like Size, it also transforms the children of the
+// node. AffineAt leaves it out, so hit-testing uses the whole node.
 type Arrangement struct {
+	Name     string        // optional name used in node paths
 	Offset   geom.Point    // distance between parent and pivot
 	Pivot    geom.Point    // point on sized, unrotated node
 	Size     *geom.Point   // optional bounding rectangle for scaling
 	Rotation float32       // radians counter-clockwise
 	SubTex   sprite.SubTex // optional Node Texture
+	Place    *f32.Affine   // optional, where SubTex is drawn in the node
+	Flipbook *Flipbook     // optional, animates SubTex and Place
+	Tint     color.Color   // optional color multiplied with SubTex
+	Fade     float32       // SubTex transparency, from 0 (opaque) to 1
 	Hidden   bool
 
 	T0, T1    clock.Time
 	Transform Transform
 
-	// TODO: Physics *physics.Physics
+	Physics *physics.Physics // optional, moves Offset
+
+	layers []layerTransform // transforms assigned by Animation layers
+	blend  *blend           // fades out the pose of an interrupted Transform
 }
 
 func (ar *Arrangement) Arrange(e sprite.Engine, n *sprite.Node, t clock.Time) {
+	if ar.Physics != nil {
+		ar.Physics.Step(&ar.Offset, t)
+	}
 	if ar.Hidden {
 		e.SetTransform(n, f32.Affine{})
 		return
 	}
 
+	ar2 := ar.pose(t)
+	if ar2.Fade >= 1 {
+		e.SetTransform(n, f32.Affine{})
+		return
+	}
+	if ar2.Flipbook != nil {
+		ar2.SubTex, ar2.Place = ar2.Flipbook.frame(t)
+	}
+	subTex := ar2.SubTex
+	if subTex.T != nil && !untinted[subTex.T] && (ar2.Tint != nil || ar2.Fade > 0) {
+		var err error
+		subTex, err = tint.SubTex(e, subTex, ar2.color())
+		if err != nil {
+			// Report each texture once, then draw it untinted.
+			log.Printf("animation.Arrangement: %v", err)
+			untinted[ar2.SubTex.T] = true
+			subTex = ar2.SubTex
+		}
+	}
+	e.SetSubTex(n, subTex)
+	a := ar2.Affine()
+	if ar2.Place != nil {
+		a.Mul(&a, ar2.Place)
+	}
+	e.SetTransform(n, a)
+}
+
+// untinted records the textures that could not be tinted.
+var untinted = make(map[sprite.Texture]bool)
+
+// layerTransform is a Transform and its timing.
+type layerTransform struct {
+	T0, T1    clock.Time
+	Transform Transform
+}
+
+func (lt *layerTransform) apply(ar *Arrangement, t clock.Time) {
+	if lt.Transform.Transformer == nil {
+		return
+	}
+	fn := lt.Transform.Tween
+	if fn == nil {
+		fn = clock.Linear
+	}
+	lt.Transform.Transformer.Transform(ar, fn(lt.T0, lt.T1, t))
+}
+
+// transform returns the transform in slot, where slot 0 is T0, T1 and
+// Transform, and each further slot belongs to an Animation layer.
+func (ar *Arrangement) transform(slot int) layerTransform {
+	if slot == 0 {
+		return layerTransform{T0: ar.T0, T1: ar.T1, Transform: ar.Transform}
+	}
+	if slot > len(ar.layers) {
+		return layerTransform{}
+	}
+	return ar.layers[slot-1]
+}
+
+func (ar *Arrangement) setTransform(slot int, lt layerTransform) {
+	if slot == 0 {
+		ar.T0, ar.T1, ar.Transform = lt.T0, lt.T1, lt.Transform
+		return
+	}
+	for len(ar.layers) < slot {
+		ar.layers = append(ar.layers, layerTransform{})
+	}
+	ar.layers[slot-1] = lt
+}
+
+// pose returns the Arrangement as it is drawn at time t.
+func (ar *Arrangement) pose(t clock.Time) Arrangement {
 	ar2 := *ar
-	if ar.Transform.Transformer != nil {
-		fn := ar.Transform.Tween
-		if fn == nil {
-			fn = clock.Linear
+	main := ar.transform(0)
+	main.apply(&ar2, t)
+	for i := range ar.layers {
+		ar.layers[i].apply(&ar2, t)
+	}
+	if ar.blend != nil {
+		if t < ar.blend.t1 {
+			ar.blend.apply(&ar2, 1-clock.Linear(ar.blend.t0, ar.blend.t1, t))
+		} else {
+			ar.blend = nil
 		}
-		tween := fn(ar.T0, ar.T1, t)
-		ar.Transform.Transformer.Transform(&ar2, tween)
 	}
-	e.SetSubTex(n, ar2.SubTex)
-	e.SetTransform(n, ar2.Affine())
+	return ar2
+}
+
+// addSize grows Size by dx, dy, if ar has a Size.
+//
+// A pose is a shallow copy of its Arrangement and shares its Size, so
+// Transforms and blends must change Size only through addSize, which
+// replaces it rather than writing through the pointer.
+func (ar *Arrangement) addSize(dx, dy geom.Pt) {
+	if ar.Size == nil {
+		return
+	}
+	ar.Size = &geom.Point{X: ar.Size.X + dx, Y: ar.Size.Y + dy}
+}
+
+// blend is the difference between two poses of an Arrangement.
+type blend struct {
+	t0, t1   clock.Time
+	offset   geom.Point
+	pivot    geom.Point
+	size     geom.Point
+	rotation float32
+	fade     float32
+}
+
+// blendFrom starts blending ar from the pose from, over times t0 to t1.
+func (ar *Arrangement) blendFrom(from *Arrangement, t0, t1 clock.Time) {
+	// Other transforms contribute to both poses, so compare
+	// with the pose at t0 rather than with ar itself.
+	ar.blend = nil
+	to := ar.pose(t0)
+	b := &blend{
+		t0:       t0,
+		t1:       t1,
+		offset:   geom.Point{X: from.Offset.X - to.Offset.X, Y: from.Offset.Y - to.Offset.Y},
+		pivot:    geom.Point{X: from.Pivot.X - to.Pivot.X, Y: from.Pivot.Y - to.Pivot.Y},
+		rotation: from.Rotation - to.Rotation,
+		fade:     from.Fade - to.Fade,
+	}
+	if from.Size != nil && to.Size != nil {
+		b.size = geom.Point{X: from.Size.X - to.Size.X, Y: from.Size.Y - to.Size.Y}
+	}
+	ar.blend = b
+}
+
+func (b *blend) apply(ar *Arrangement, f float32) {
+	k := geom.Pt(f)
+	ar.Offset.X += b.offset.X * k
+	ar.Offset.Y += b.offset.Y * k
+	ar.Pivot.X += b.pivot.X * k
+	ar.Pivot.Y += b.pivot.Y * k
+	ar.Rotation += b.rotation * f
+	ar.Fade += b.fade * f
+	ar.addSize(b.size.X*k, b.size.Y*k)
+}
+
+// color is the color the SubTex is multiplied by, combining Tint and Fade.
+func (ar *Arrangement) color() color.Color {
+	c := color.NRGBA{0xff, 0xff, 0xff, 0xff}
+	if ar.Tint != nil {
+		c = color.NRGBAModel.Convert(ar.Tint).(color.NRGBA)
+	}
+	if ar.Fade > 0 {
+		c.A = uint8(float32(c.A) * (1 - ar.Fade))
+	}
+	return c
+}
+
+// AffineAt returns the transform ar gives its node at time t, including
+// any Transform in progress. A hidden Arrangement has a zero transform.
+func (ar *Arrangement) AffineAt(t clock.Time) f32.Affine {
+	if ar.Hidden {
+		return f32.Affine{}
+	}
+	ar2 := ar.pose(t)
+	if ar2.Fade >= 1 {
+		return f32.Affine{}
+	}
+	return ar2.Affine()
 }
 
 func (ar *Arrangement) Affine() f32.Affine {
@@ -154,7 +685,7 @@ func (r Rotate) Transform(ar *Arrangement, tween float32) {
 	ar.Rotation += tween * float32(r)
 }
 
-func (r Rotate) String() string { return fmt.Sprintf("Rotate(%d)", r) }
+func (r Rotate) String() string { return fmt.Sprintf("Rotate(%g)", r) }
 
 // Move moves the Arrangement offset.
 type Move geom.Point
@@ -165,3 +696,71 @@ func (m Move) Transform(ar *Arrangement, tween float32) {
 }
 
 func (m Move) String() string { return fmt.Sprintf("Move(%s,%s)", m.X, m.Y) }
+
+// Scale scales the Arrangement size around its pivot, reaching a
+// factor of Scale at the end of the tween. It has no effect on an
+// Arrangement without a Size.
+type Scale float32
+
+func (s Scale) Transform(ar *Arrangement, tween float32) {
+	if ar.Size == nil {
+		return
+	}
+	k := geom.Pt(1 + (float32(s)-1)*tween)
+	ar.addSize(ar.Size.X*(k-1), ar.Size.Y*(k-1))
+	ar.Pivot.X *= k
+	ar.Pivot.Y *= k
+}
+
+func (s Scale) String() string { return fmt.Sprintf("Scale(%g)", s) }
+
+// Fade increases the Arrangement transparency. A negative Fade makes the
+// Arrangement more opaque.
+type Fade float32
+
+func (f Fade) Transform(ar *Arrangement, tween float32) {
+	ar.Fade += tween * float32(f)
+	if ar.Fade < 0 {
+		ar.Fade = 0
+	}
+}
+
+func (f Fade) String() string { return fmt.Sprintf("Fade(%g)", f) }
+
+// TintTo changes the Arrangement tint to the given color.
+// An Arrangement without a Tint starts from white.
+type TintTo color.NRGBA
+
+func (c TintTo) Transform(ar *Arrangement, tween float32) {
+	from := color.NRGBA{0xff, 0xff, 0xff, 0xff}
+	if ar.Tint != nil {
+		from = color.NRGBAModel.Convert(ar.Tint).(color.NRGBA)
+	}
+	lerp := func(a, b uint8) uint8 {
+		return uint8(float32(a) + (float32(b)-float32(a))*tween)
+	}
+	ar.Tint = color.NRGBA{
+		R: lerp(from.R, c.R),
+		G: lerp(from.G, c.G),
+		B: lerp(from.B, c.B),
+		A: lerp(from.A, c.A),
+	}
+}
+
+func (c TintTo) String() string {
+	return fmt.Sprintf("TintTo(#%02x%02x%02x%02x)", c.R, c.G, c.B, c.A)
+}
+
+// SwapSubTex replaces the Arrangement SubTex once the tween reaches At.
+// In a Sequence or Clip, the tween is that of its part of the range, so
+// At 0 swaps when that part starts.
+type SwapSubTex struct {
+	At     float32
+	SubTex sprite.SubTex
+}
+
+func (s SwapSubTex) Transform(ar *Arrangement, tween float32) {
+	if tween >= s.At {
+		ar.SubTex = s.SubTex
+	}
+}
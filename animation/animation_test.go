@@ -0,0 +1,127 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package animation
+
+import (
+	"image"
+	"strings"
+	"testing"
+
+	"golang.org/x/mobile/f32"
+	"golang.org/x/mobile/sprite"
+	"golang.org/x/mobile/sprite/clock"
+)
+
+func TestValidateZeroCycle(t *testing.T) {
+	windy := func(*Animation, clock.Time) bool { return false }
+	tests := []struct {
+		name   string
+		states map[string]State
+		cycle  string // expected in the error, or "" for no error
+	}{
+		{
+			name: "next",
+			states: map[string]State{
+				"a": {Next: "b"},
+				"b": {Next: "a"},
+			},
+			cycle: "a -> b -> a",
+		},
+		{
+			name: "next with duration",
+			states: map[string]State{
+				"a": {Next: "b"},
+				"b": {Duration: 1, Next: "a"},
+			},
+		},
+		{
+			name: "edge to self",
+			states: map[string]State{
+				"a": {Edges: []Edge{{Next: "a"}}},
+			},
+			cycle: "a -> a",
+		},
+		{
+			name: "edge",
+			states: map[string]State{
+				"a": {Edges: []Edge{{Next: "b"}}},
+				"b": {Duration: 1, Edges: []Edge{{Next: "a"}}},
+				"c": {Edges: []Edge{{Next: "b", Weight: 2}, {Next: "c", Weight: 1}}},
+			},
+			cycle: "c -> c",
+		},
+		{
+			name: "guarded edge",
+			states: map[string]State{
+				"a": {Next: "b", Edges: []Edge{{Next: "a", If: windy}}},
+				"b": {Duration: 1, Next: "a"},
+			},
+			cycle: "a -> a",
+		},
+		{
+			name: "next after guarded edge",
+			states: map[string]State{
+				"a": {Next: "a", Edges: []Edge{{Next: "b", If: windy}}},
+				"b": {Duration: 1, Next: "a"},
+			},
+			cycle: "a -> a",
+		},
+		{
+			name: "next replaced by edge",
+			states: map[string]State{
+				"a": {Next: "a", Edges: []Edge{{Next: "b"}}},
+				"b": {Duration: 1, Next: "a"},
+			},
+		},
+		{
+			name: "triggered edge",
+			states: map[string]State{
+				"a": {Duration: 1, Next: "b"},
+				"b": {Edges: []Edge{{Next: "b", Trigger: "fire"}}},
+			},
+		},
+	}
+	for _, test := range tests {
+		a := &Animation{Current: "a", States: test.states}
+		err := a.Validate()
+		switch {
+		case test.cycle == "" && err != nil:
+			t.Errorf("%s: Validate: %v", test.name, err)
+		case test.cycle != "" && err == nil:
+			t.Errorf("%s: Validate succeeded, want cycle %s", test.name, test.cycle)
+		case test.cycle != "" && !strings.Contains(err.Error(), "cycle "+test.cycle):
+			t.Errorf("%s: Validate: %v, want cycle %s", test.name, err, test.cycle)
+		}
+	}
+}
+
+func TestFlipbookPlaces(t *testing.T) {
+	var p f32.Affine
+	p.Identity()
+	f := &Flipbook{
+		Frames: []sprite.SubTex{
+			{R: image.Rect(0, 0, 1, 1)},
+			{R: image.Rect(1, 0, 2, 1)},
+			{R: image.Rect(2, 0, 3, 1)},
+		},
+		Places:    []*f32.Affine{nil, &p},
+		FrameTime: 10,
+	}
+	for _, tt := range []struct {
+		t     clock.Time
+		frame int
+		place *f32.Affine
+	}{
+		{0, 0, nil},
+		{10, 1, &p},
+		{25, 2, nil}, // Places is shorter than Frames
+		{30, 0, nil},
+	} {
+		x, place := f.frame(tt.t)
+		if x != f.Frames[tt.frame] || place != tt.place {
+			t.Errorf("at %d: frame %v, place %p, want frame %d, place %p", tt.t, x.R, place, tt.frame, tt.place)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package animation
+
+import "golang.org/x/mobile/sprite/clock"
+
+// Parallel is a Transformer that applies several Transformers at once.
+type Parallel []Transformer
+
+func (p Parallel) Transform(ar *Arrangement, tween float32) {
+	for _, t := range p {
+		t.Transform(ar, tween)
+	}
+}
+
+// Sequence is a Transformer that applies its Transformers one after
+// another, dividing the tween range evenly between them. A Transformer
+// is not applied until its part of the range starts.
+//
+// For an uneven division, use a Parallel of Clips.
+type Sequence []Transformer
+
+func (s Sequence) Transform(ar *Arrangement, tween float32) {
+	n := float32(len(s))
+	for i, t := range s {
+		f := tween*n - float32(i)
+		if f < 0 {
+			break
+		}
+		t.Transform(ar, clamp(f))
+	}
+}
+
+// Clip is a Transformer that applies a Transformer over part of the
+// tween range. Before Start the Transformer is not applied, after End it
+// is at 1.
+type Clip struct {
+	Start, End  float32
+	Tween       func(t0, t1, t clock.Time) float32 // optional easing within the clip
+	Transformer Transformer
+}
+
+func (c Clip) Transform(ar *Arrangement, tween float32) {
+	if tween < c.Start {
+		return
+	}
+	f := float32(1)
+	if c.End > c.Start {
+		f = clamp((tween - c.Start) / (c.End - c.Start))
+	}
+	if c.Tween != nil {
+		f = ease(c.Tween, f)
+	}
+	c.Transformer.Transform(ar, f)
+}
+
+// Delay returns a Clip that applies t once the tween reaches d.
+func Delay(d float32, t Transformer) Clip {
+	return Clip{Start: d, End: 1, Transformer: t}
+}
+
+func clamp(f float32) float32 {
+	switch {
+	case f < 0:
+		return 0
+	case f > 1:
+		return 1
+	}
+	return f
+}
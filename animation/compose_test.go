@@ -0,0 +1,57 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package animation
+
+import (
+	"image"
+	"testing"
+
+	"golang.org/x/mobile/sprite"
+)
+
+func TestSwapInSegment(t *testing.T) {
+	old := sprite.SubTex{R: image.Rect(0, 0, 1, 1)}
+	popped := sprite.SubTex{R: image.Rect(1, 0, 2, 1)}
+	swap := SwapSubTex{SubTex: popped}
+	for _, tt := range []struct {
+		name  string
+		tr    Transformer
+		tween float32
+		want  sprite.SubTex
+	}{
+		{"sequence before", Sequence{Fade(1), swap}, 0, old},
+		{"sequence before", Sequence{Fade(1), swap}, 0.49, old},
+		{"sequence start", Sequence{Fade(1), swap}, 0.5, popped},
+		{"sequence end", Sequence{Fade(1), swap}, 1, popped},
+		{"delay before", Delay(0.5, swap), 0.25, old},
+		{"delay start", Delay(0.5, swap), 0.5, popped},
+		{"clip before", Clip{Start: 0.5, End: 0.5, Transformer: swap}, 0.25, old},
+		{"clip after", Clip{Start: 0.5, End: 0.5, Transformer: swap}, 0.75, popped},
+	} {
+		ar := &Arrangement{SubTex: old}
+		tt.tr.Transform(ar, tt.tween)
+		if ar.SubTex != tt.want {
+			t.Errorf("%s: at %g, SubTex %v, want %v", tt.name, tt.tween, ar.SubTex.R, tt.want.R)
+		}
+	}
+}
+
+func TestSequence(t *testing.T) {
+	s := Sequence{Rotate(1), Rotate(2), Rotate(4)}
+	for _, tt := range []struct {
+		tween, want float32
+	}{
+		{0, 0},
+		{1.0 / 6, 0.5},
+		{0.5, 2},
+		{1, 7},
+	} {
+		ar := new(Arrangement)
+		s.Transform(ar, tt.tween)
+		if d := ar.Rotation - tt.want; d < -1e-5 || d > 1e-5 {
+			t.Errorf("at %g, Rotation %g, want %g", tt.tween, ar.Rotation, tt.want)
+		}
+	}
+}
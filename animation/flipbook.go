@@ -0,0 +1,79 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package animation
+
+import (
+	"golang.org/x/mobile/f32"
+	"golang.org/x/mobile/sprite"
+	"golang.org/x/mobile/sprite/clock"
+)
+
+// FlipMode is how a Flipbook continues after its last frame.
+type FlipMode int
+
+const (
+	Loop     FlipMode = iota // start again from the first frame
+	PingPong                 // play backwards to the first frame, then forwards
+	Once                     // hold the last frame
+)
+
+// Flipbook cycles through a sequence of frames from a sprite sheet.
+//
+// Assign it to Arrangement.Flipbook to animate the SubTex of a node.
+type Flipbook struct {
+	Frames    []sprite.SubTex
+	Places    []*f32.Affine // optional, the Arrangement.Place of each frame
+	FrameTime clock.Time    // duration of each frame
+	Mode      FlipMode
+	Start     clock.Time // time the first frame is shown
+}
+
+// Frame returns the frame shown at time t.
+func (f *Flipbook) Frame(t clock.Time) sprite.SubTex {
+	if len(f.Frames) == 0 {
+		return sprite.SubTex{}
+	}
+	return f.Frames[f.index(t)]
+}
+
+// frame returns the frame shown at time t and its place.
+func (f *Flipbook) frame(t clock.Time) (sprite.SubTex, *f32.Affine) {
+	if len(f.Frames) == 0 {
+		return sprite.SubTex{}, nil
+	}
+	i := f.index(t)
+	if i < len(f.Places) {
+		return f.Frames[i], f.Places[i]
+	}
+	return f.Frames[i], nil
+}
+
+// index returns the index in Frames of the frame shown at time t.
+func (f *Flipbook) index(t clock.Time) int {
+	n := len(f.Frames)
+	if f.FrameTime <= 0 || t < f.Start {
+		return 0
+	}
+	i := int((t - f.Start) / f.FrameTime)
+	switch f.Mode {
+	case Loop:
+		i %= n
+	case PingPong:
+		if n > 1 {
+			// A cycle is 0, 1, ..., n-1, n-2, ..., 1.
+			i %= 2 * (n - 1)
+			if i >= n {
+				i = 2*(n-1) - i
+			}
+		} else {
+			i = 0
+		}
+	case Once:
+		if i >= n {
+			i = n - 1
+		}
+	}
+	return i
+}
@@ -0,0 +1,79 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package animation
+
+import (
+	"golang.org/x/mobile/geom"
+	"golang.org/x/mobile/sprite/clock"
+)
+
+// Key is a waypoint in a Keyframes track.
+//
+// Its values are relative to the Arrangement at the start of the track,
+// in the same way as Move and Rotate.
+type Key struct {
+	At       float32    // position in the tween, from 0 to 1
+	Offset   geom.Point // added to Arrangement.Offset
+	Rotation float32    // added to Arrangement.Rotation
+	Size     geom.Point // added to Arrangement.Size, if it is set
+
+	// Tween eases the segment ending at this key. If nil, the segment
+	// is linear.
+	Tween func(t0, t1, t clock.Time) float32
+}
+
+// Keyframes is a Transformer that moves through a sequence of keys.
+//
+// Keys must be sorted by At. The track starts from an implicit key with
+// all values zero at At 0, and holds the last key once it is passed.
+type Keyframes []Key
+
+func (k Keyframes) Transform(ar *Arrangement, tween float32) {
+	var prev Key
+	for _, key := range k {
+		if tween > key.At {
+			prev = key
+			continue
+		}
+		f := float32(1)
+		if key.At > prev.At {
+			f = (tween - prev.At) / (key.At - prev.At)
+		}
+		if key.Tween != nil {
+			f = ease(key.Tween, f)
+		}
+		prev.lerp(&key, f).apply(ar)
+		return
+	}
+	prev.apply(ar)
+}
+
+func (k *Key) lerp(k1 *Key, f float32) *Key {
+	t := geom.Pt(f)
+	return &Key{
+		Offset: geom.Point{
+			X: k.Offset.X + (k1.Offset.X-k.Offset.X)*t,
+			Y: k.Offset.Y + (k1.Offset.Y-k.Offset.Y)*t,
+		},
+		Rotation: k.Rotation + (k1.Rotation-k.Rotation)*f,
+		Size: geom.Point{
+			X: k.Size.X + (k1.Size.X-k.Size.X)*t,
+			Y: k.Size.Y + (k1.Size.Y-k.Size.Y)*t,
+		},
+	}
+}
+
+func (k *Key) apply(ar *Arrangement) {
+	ar.Offset.X += k.Offset.X
+	ar.Offset.Y += k.Offset.Y
+	ar.Rotation += k.Rotation
+	ar.addSize(k.Size.X, k.Size.Y)
+}
+
+// ease evaluates the tween function fn at fraction f of its range.
+func ease(fn func(t0, t1, t clock.Time) float32, f float32) float32 {
+	const res = 1 << 16
+	return fn(0, res, clock.Time(f*res))
+}
@@ -0,0 +1,359 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package animation
+
+import (
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"io"
+
+	"golang.org/x/mobile/geom"
+	"golang.org/x/mobile/sprite"
+	"golang.org/x/mobile/sprite/clock"
+)
+
+// Load reads an Animation described in JSON.
+//
+// The file names the initial state and describes each state. Transforms
+// are keyed by node path, and each names a Tween and one Transformer:
+//
+//	{
+//		"Current": "init",
+//		"States": {
+//			"init": {"Duration": 60, "Edges": [
+//				{"Next": "falling", "Weight": 2},
+//				{"Next": "tumbling", "If": "windy"},
+//				{"Next": "falling", "Trigger": "drop"}
+//			]},
+//			"falling": {
+//				"Duration": 240,
+//				"Next": "init",
+//				"Transforms": {
+//					"gopher":   {"Tween": "easeIn", "Move": {"X": 0, "Y": 320}},
+//					"arm":      {"Rotate": 1.2},
+//					"arm/fold": {"Func": "moveArm"},
+//					"balloon":  {"Keyframes": [
+//						{"At": 0.5, "Offset": {"X": 10, "Y": -20}, "Tween": "easeOut"},
+//						{"At": 1, "Offset": {"X": 0, "Y": -40}, "Rotation": 0.2}
+//					]},
+//					"gopher2":  {"Parallel": [
+//						{"Move": {"X": 0, "Y": 320}},
+//						{"Rotate": 3.14, "Tween": "easeIn", "Clip": [0.5, 1]}
+//					]},
+//					"balloon2": {"Sequence": [
+//						{"Scale": 4},
+//						{"TintTo": {"R": 255, "G": 0, "B": 0, "A": 255}},
+//						{"SwapSubTex": {"At": 0, "SubTex": "popped"}},
+//						{"Fade": 1}
+//					]}
+//				}
+//			},
+//			"tumbling": {"Duration": 30, "Next": "init"}
+//		}
+//	}
+//
+// Crossfade and Layers set the corresponding Animation fields. Each layer
+// has a Name, Current and States, described as above.
+//
+// Unknown keys are errors, so that a misspelt key is not ignored.
+// The states are checked with Validate. Paths are resolved, and checked,
+// when the Animation is first arranged.
+// Func transformers, SubTex names and edge guards (If) are resolved using b.
+//
+// Parallel and Sequence hold nested transforms. Clip restricts a transform
+// to part of the tween range, and its Tween then eases within the clip.
+// A Tween is one of "linear" (the default), "easeIn", "easeOut",
+// "easeInOut", or a name in b.Tweens.
+func Load(r io.Reader, b *Bindings) (*Animation, error) {
+	var f fileAnimation
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&f); err != nil {
+		return nil, fmt.Errorf("animation: %v", err)
+	}
+	if b == nil {
+		b = new(Bindings)
+	}
+	states, err := loadStates(f.States, b)
+	if err != nil {
+		return nil, fmt.Errorf("animation: %v", err)
+	}
+	a := &Animation{
+		Current:   f.Current,
+		States:    states,
+		Crossfade: clock.Time(f.Crossfade),
+	}
+	for _, fl := range f.Layers {
+		states, err := loadStates(fl.States, b)
+		if err != nil {
+			return nil, fmt.Errorf("animation: layer %q: %v", fl.Name, err)
+		}
+		a.Layers = append(a.Layers, &Layer{
+			Name:    fl.Name,
+			Current: fl.Current,
+			States:  states,
+		})
+	}
+	if err := a.Validate(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Bindings connects the names used in an animation file to Go values.
+type Bindings struct {
+	SubTex       map[string]sprite.SubTex
+	Transformers map[string]Transformer
+	Guards       map[string]func(a *Animation, t clock.Time) bool
+	Tweens       map[string]func(t0, t1, t clock.Time) float32
+}
+
+var tweens = map[string]func(t0, t1, t clock.Time) float32{
+	"linear":    clock.Linear,
+	"easeIn":    clock.EaseIn,
+	"easeOut":   clock.EaseOut,
+	"easeInOut": clock.EaseInOut,
+}
+
+type fileAnimation struct {
+	Current   string
+	States    map[string]fileState
+	Crossfade int
+	Layers    []fileLayer
+}
+
+type fileLayer struct {
+	Name    string
+	Current string
+	States  map[string]fileState
+}
+
+func loadStates(fstates map[string]fileState, b *Bindings) (map[string]State, error) {
+	states := make(map[string]State)
+	for stateName, fs := range fstates {
+		s := State{
+			Duration: fs.Duration,
+			Next:     fs.Next,
+		}
+		for _, fe := range fs.Edges {
+			e := Edge{
+				Next:    fe.Next,
+				Trigger: fe.Trigger,
+				Weight:  fe.Weight,
+			}
+			if fe.If != "" {
+				e.If = b.Guards[fe.If]
+				if e.If == nil {
+					return nil, fmt.Errorf("state %q: unknown guard %q", stateName, fe.If)
+				}
+			}
+			s.Edges = append(s.Edges, e)
+		}
+		if len(fs.Transforms) > 0 {
+			s.Transforms = make(map[string]Transform)
+		}
+		for path, ft := range fs.Transforms {
+			t, err := ft.transform(b)
+			if err != nil {
+				return nil, fmt.Errorf("state %q: node %q: %v", stateName, path, err)
+			}
+			s.Transforms[path] = t
+		}
+		states[stateName] = s
+	}
+	return states, nil
+}
+
+type fileState struct {
+	Duration   int
+	Next       string
+	Edges      []fileEdge
+	Transforms map[string]fileTransform
+}
+
+type fileEdge struct {
+	Next    string
+	Trigger string
+	If      string
+	Weight  int
+}
+
+type fileTransform struct {
+	Tween      string
+	Clip       *[2]float32
+	Move       *geom.Point
+	Rotate     *float32
+	Scale      *float32
+	Fade       *float32
+	TintTo     *color.NRGBA
+	SwapSubTex *fileSwap
+	Keyframes  []fileKey
+	Parallel   []fileTransform
+	Sequence   []fileTransform
+	Func       string
+}
+
+type fileSwap struct {
+	At     float32
+	SubTex string
+}
+
+type fileKey struct {
+	At       float32
+	Offset   geom.Point
+	Rotation float32
+	Size     geom.Point
+	Tween    string
+}
+
+func (b *Bindings) tween(name string) (func(t0, t1, t clock.Time) float32, error) {
+	if name == "" {
+		return nil, nil
+	}
+	if fn := tweens[name]; fn != nil {
+		return fn, nil
+	}
+	if fn := b.Tweens[name]; fn != nil {
+		return fn, nil
+	}
+	return nil, fmt.Errorf("unknown tween %q", name)
+}
+
+func (ft *fileTransform) transform(b *Bindings) (Transform, error) {
+	tween, err := b.tween(ft.Tween)
+	if err != nil {
+		return Transform{}, err
+	}
+	tr, err := ft.transformer(b)
+	if err != nil {
+		return Transform{}, err
+	}
+	if ft.Clip == nil {
+		return Transform{Tween: tween, Transformer: tr}, nil
+	}
+	return Transform{
+		Transformer: Clip{
+			Start:       ft.Clip[0],
+			End:         ft.Clip[1],
+			Tween:       tween,
+			Transformer: tr,
+		},
+	}, nil
+}
+
+// transformer builds the Transformer described by ft, ignoring its
+// Tween and Clip.
+func (ft *fileTransform) transformer(b *Bindings) (Transformer, error) {
+	var tr Transformer
+	set := func(t Transformer) error {
+		if tr != nil {
+			return fmt.Errorf("more than one transformer")
+		}
+		tr = t
+		return nil
+	}
+	if ft.Move != nil {
+		if err := set(Move(*ft.Move)); err != nil {
+			return nil, err
+		}
+	}
+	if ft.Rotate != nil {
+		if err := set(Rotate(*ft.Rotate)); err != nil {
+			return nil, err
+		}
+	}
+	if ft.Scale != nil {
+		if err := set(Scale(*ft.Scale)); err != nil {
+			return nil, err
+		}
+	}
+	if ft.Fade != nil {
+		if err := set(Fade(*ft.Fade)); err != nil {
+			return nil, err
+		}
+	}
+	if ft.TintTo != nil {
+		if err := set(TintTo(*ft.TintTo)); err != nil {
+			return nil, err
+		}
+	}
+	if ft.SwapSubTex != nil {
+		x, ok := b.SubTex[ft.SwapSubTex.SubTex]
+		if !ok {
+			return nil, fmt.Errorf("unknown SubTex %q", ft.SwapSubTex.SubTex)
+		}
+		if err := set(SwapSubTex{At: ft.SwapSubTex.At, SubTex: x}); err != nil {
+			return nil, err
+		}
+	}
+	if ft.Keyframes != nil {
+		k := make(Keyframes, len(ft.Keyframes))
+		for i, fk := range ft.Keyframes {
+			if i > 0 && fk.At < ft.Keyframes[i-1].At {
+				return nil, fmt.Errorf("keyframe %d is out of order", i)
+			}
+			k[i] = Key{
+				At:       fk.At,
+				Offset:   fk.Offset,
+				Rotation: fk.Rotation,
+				Size:     fk.Size,
+			}
+			var err error
+			if k[i].Tween, err = b.tween(fk.Tween); err != nil {
+				return nil, err
+			}
+		}
+		if err := set(k); err != nil {
+			return nil, err
+		}
+	}
+	if ft.Parallel != nil {
+		p, err := subTransformers(b, ft.Parallel)
+		if err != nil {
+			return nil, fmt.Errorf("parallel: %v", err)
+		}
+		if err := set(Parallel(p)); err != nil {
+			return nil, err
+		}
+	}
+	if ft.Sequence != nil {
+		seq, err := subTransformers(b, ft.Sequence)
+		if err != nil {
+			return nil, fmt.Errorf("sequence: %v", err)
+		}
+		if err := set(Sequence(seq)); err != nil {
+			return nil, err
+		}
+	}
+	if ft.Func != "" {
+		t := b.Transformers[ft.Func]
+		if t == nil {
+			return nil, fmt.Errorf("unknown transformer func %q", ft.Func)
+		}
+		if err := set(t); err != nil {
+			return nil, err
+		}
+	}
+	if tr == nil {
+		return nil, fmt.Errorf("no transformer")
+	}
+	return tr, nil
+}
+
+func subTransformers(b *Bindings, fts []fileTransform) ([]Transformer, error) {
+	trs := make([]Transformer, len(fts))
+	for i := range fts {
+		t, err := fts[i].transform(b)
+		if err != nil {
+			return nil, fmt.Errorf("%d: %v", i, err)
+		}
+		trs[i] = t.Transformer
+		if t.Tween != nil {
+			trs[i] = Clip{Start: 0, End: 1, Tween: t.Tween, Transformer: t.Transformer}
+		}
+	}
+	return trs, nil
+}
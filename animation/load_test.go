@@ -0,0 +1,93 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package animation
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/mobile/sprite"
+	"golang.org/x/mobile/sprite/clock"
+)
+
+// example is the example in the documentation of Load.
+const example = `{
+	"Current": "init",
+	"States": {
+		"init": {"Duration": 60, "Edges": [
+			{"Next": "falling", "Weight": 2},
+			{"Next": "tumbling", "If": "windy"},
+			{"Next": "falling", "Trigger": "drop"}
+		]},
+		"falling": {
+			"Duration": 240,
+			"Next": "init",
+			"Transforms": {
+				"gopher":   {"Tween": "easeIn", "Move": {"X": 0, "Y": 320}},
+				"arm":      {"Rotate": 1.2},
+				"arm/fold": {"Func": "moveArm"},
+				"balloon":  {"Keyframes": [
+					{"At": 0.5, "Offset": {"X": 10, "Y": -20}, "Tween": "easeOut"},
+					{"At": 1, "Offset": {"X": 0, "Y": -40}, "Rotation": 0.2}
+				]},
+				"gopher2":  {"Parallel": [
+					{"Move": {"X": 0, "Y": 320}},
+					{"Rotate": 3.14, "Tween": "easeIn", "Clip": [0.5, 1]}
+				]},
+				"balloon2": {"Sequence": [
+					{"Scale": 4},
+					{"TintTo": {"R": 255, "G": 0, "B": 0, "A": 255}},
+					{"SwapSubTex": {"At": 0, "SubTex": "popped"}},
+					{"Fade": 1}
+				]}
+			}
+		},
+		"tumbling": {"Duration": 30, "Next": "init"}
+	}
+}`
+
+var exampleBindings = &Bindings{
+	SubTex: map[string]sprite.SubTex{"popped": {}},
+	Transformers: map[string]Transformer{
+		"moveArm": TransformerFunc(func(ar *Arrangement, tween float32) {}),
+	},
+	Guards: map[string]func(*Animation, clock.Time) bool{
+		"windy": func(*Animation, clock.Time) bool { return true },
+	},
+}
+
+func TestLoadExample(t *testing.T) {
+	a, err := Load(strings.NewReader(example), exampleBindings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Current != "init" {
+		t.Errorf("Current = %q, want init", a.Current)
+	}
+	if got := len(a.States["init"].Edges); got != 3 {
+		t.Errorf("init has %d edges, want 3", got)
+	}
+	falling := a.States["falling"]
+	if falling.Duration != 240 || falling.Next != "init" {
+		t.Errorf("falling = {Duration: %d, Next: %q}, want {240, init}", falling.Duration, falling.Next)
+	}
+	if got := len(falling.Transforms); got != 6 {
+		t.Errorf("falling has %d transforms, want 6", got)
+	}
+}
+
+func TestLoadUnknownKey(t *testing.T) {
+	for _, src := range []string{
+		`{"Current": "init", "States": {"init": {"Durration": 60}}}`,
+		`{"Current": "init", "States": {"init": {"Duration": 60, "Tranforms": {}}}}`,
+		`{"Current": "init", "States": {"init": {"Duration": 60, "Transforms": {"a": {"Mvoe": {"X": 1}}}}}}`,
+		`{"Name": "main", "Current": "init", "States": {"init": {}}}`,
+		`{"Current": "init", "States": {"init": {}}, "Layers": [{"Name": "l", "Curent": "a"}]}`,
+	} {
+		if _, err := Load(strings.NewReader(src), nil); err == nil {
+			t.Errorf("Load(%s) succeeded, want unknown key error", src)
+		}
+	}
+}
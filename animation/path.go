@@ -0,0 +1,41 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package animation
+
+import (
+	"strings"
+
+	"golang.org/x/mobile/sprite"
+)
+
+// Lookup finds the node at path, relative to root.
+//
+// A path is a slash-separated list of names, such as "arm/fold2/top".
+// Each name selects the first child whose Arranger is an *Arrangement
+// with that Name. The empty path is root itself.
+//
+// Lookup returns nil if no node matches.
+func Lookup(root *sprite.Node, path string) *sprite.Node {
+	n := root
+	for _, name := range strings.Split(path, "/") {
+		if name == "" {
+			continue
+		}
+		n = child(n, name)
+		if n == nil {
+			return nil
+		}
+	}
+	return n
+}
+
+func child(n *sprite.Node, name string) *sprite.Node {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if ar, ok := c.Arranger.(*Arrangement); ok && ar.Name == name {
+			return c
+		}
+	}
+	return nil
+}
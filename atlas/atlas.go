@@ -0,0 +1,224 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package atlas loads sprite sheets described by a JSON sidecar file.
+//
+// The sidecar of "balloon_sheet.png" is "balloon_sheet.json". It uses a
+// subset of the TexturePacker "JSON (Hash)" format:
+//
+//	{
+//		"frames": {
+//			"arm": {"frame": {"x": 0, "y": 0, "w": 194, "h": 42}},
+//			"pad": {"frame": {"x": 194, "y": 0, "w": 100, "h": 286}}
+//		},
+//		"meta": {"image": "balloon_sheet.png", "size": {"w": 294, "h": 634}}
+//	}
+//
+// A trimmed frame had transparent borders removed when it was packed.
+// A SubTex is drawn stretched over its node, so a trimmed frame is drawn
+// with the transform returned by Sprite or Frame.Place, which puts it
+// where it was in the original image. Rotated frames are not supported.
+package atlas
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	_ "image/png"
+	"io"
+	"path"
+	"strings"
+
+	"golang.org/x/mobile/app"
+	"golang.org/x/mobile/f32"
+	"golang.org/x/mobile/sprite"
+
+	"github.com/crawshaw/balloon/tint"
+)
+
+// Descriptor describes the frames packed into a sprite sheet.
+type Descriptor struct {
+	Frames map[string]Frame `json:"frames"`
+	Meta   Meta             `json:"meta"`
+}
+
+// Frame is a named image in a sprite sheet.
+//
+// A trimmed frame had transparent borders removed when it was packed.
+// SpriteSourceSize is where the trimmed frame sits in the original image,
+// and SourceSize is the size of the original image.
+type Frame struct {
+	Frame            Rect `json:"frame"`
+	Rotated          bool `json:"rotated"`
+	Trimmed          bool `json:"trimmed"`
+	SpriteSourceSize Rect `json:"spriteSourceSize"`
+	SourceSize       Size `json:"sourceSize"`
+}
+
+// Place returns the transform of the unit square of the original image
+// of f to where f sits in it, or nil if f is not trimmed. Assign it to
+// the Place of an animation.Arrangement drawing f.
+func (f Frame) Place() *f32.Affine {
+	if !f.Trimmed {
+		return nil
+	}
+	src, w, h := f.SpriteSourceSize, float32(f.SourceSize.W), float32(f.SourceSize.H)
+	a := new(f32.Affine)
+	a.Identity()
+	a.Translate(a, float32(src.X)/w, float32(src.Y)/h)
+	a.Scale(a, float32(src.W)/w, float32(src.H)/h)
+	return a
+}
+
+// Meta describes a sprite sheet image.
+type Meta struct {
+	Image string `json:"image"`
+	Size  Size   `json:"size"`
+}
+
+// Rect is a rectangle in pixels.
+type Rect struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+// Rectangle converts r to an image.Rectangle.
+func (r Rect) Rectangle() image.Rectangle {
+	return image.Rect(r.X, r.Y, r.X+r.W, r.Y+r.H)
+}
+
+// Size is a size in pixels.
+type Size struct {
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+// Decode reads a descriptor.
+func Decode(r io.Reader) (*Descriptor, error) {
+	d := new(Descriptor)
+	if err := json.NewDecoder(r).Decode(d); err != nil {
+		return nil, err
+	}
+	for name, f := range d.Frames {
+		if f.Rotated {
+			return nil, fmt.Errorf("frame %q is rotated", name)
+		}
+		if f.Frame.W <= 0 || f.Frame.H <= 0 {
+			return nil, fmt.Errorf("frame %q is empty", name)
+		}
+		if f.Trimmed {
+			src := f.SpriteSourceSize
+			if src.W != f.Frame.W || src.H != f.Frame.H {
+				return nil, fmt.Errorf("frame %q is %dx%d, but its spriteSourceSize is %dx%d", name, f.Frame.W, f.Frame.H, src.W, src.H)
+			}
+			if !src.Rectangle().In(image.Rect(0, 0, f.SourceSize.W, f.SourceSize.H)) {
+				return nil, fmt.Errorf("frame %q spriteSourceSize is outside its %dx%d sourceSize", name, f.SourceSize.W, f.SourceSize.H)
+			}
+		}
+	}
+	return d, nil
+}
+
+// Encode writes a descriptor.
+func (d *Descriptor) Encode(w io.Writer) error {
+	b, err := json.MarshalIndent(d, "", "\t")
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+	_, err = w.Write(b)
+	return err
+}
+
+// Atlas is a sprite sheet loaded into an Engine.
+type Atlas struct {
+	Texture    sprite.Texture
+	Descriptor *Descriptor
+
+	name string // sidecar file name
+}
+
+// Open loads the named sprite sheet and its sidecar descriptor
+// using app.Open.
+//
+// The texture is loaded with tint.LoadTexture, so its frames can be
+// tinted.
+func Open(e sprite.Engine, name string) (*Atlas, error) {
+	sidecar := strings.TrimSuffix(name, path.Ext(name)) + ".json"
+	d, err := decodeAsset(sidecar)
+	if err != nil {
+		return nil, err
+	}
+	if d.Meta.Image != "" && d.Meta.Image != path.Base(name) {
+		return nil, fmt.Errorf("atlas: %s describes %s, not %s", sidecar, d.Meta.Image, path.Base(name))
+	}
+
+	f, err := app.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	m, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("atlas: %s: %v", name, err)
+	}
+	for frameName, fr := range d.Frames {
+		if !fr.Frame.Rectangle().In(m.Bounds()) {
+			return nil, fmt.Errorf("atlas: %s: frame %q is outside the %v image", sidecar, frameName, m.Bounds().Size())
+		}
+	}
+
+	t, err := tint.LoadTexture(e, m)
+	if err != nil {
+		return nil, err
+	}
+	return &Atlas{
+		Texture:    t,
+		Descriptor: d,
+		name:       sidecar,
+	}, nil
+}
+
+func decodeAsset(name string) (*Descriptor, error) {
+	f, err := app.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	d, err := Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("atlas: %s: %v", name, err)
+	}
+	return d, nil
+}
+
+// Frame returns the named frame.
+func (a *Atlas) Frame(name string) (Frame, error) {
+	f, ok := a.Descriptor.Frames[name]
+	if !ok {
+		return Frame{}, fmt.Errorf("atlas: %s has no frame %q", a.name, name)
+	}
+	return f, nil
+}
+
+// SubTex returns the named frame as a SubTex. A trimmed frame is only
+// drawn in the right place with its Place; see Sprite.
+func (a *Atlas) SubTex(name string) (sprite.SubTex, error) {
+	f, err := a.Frame(name)
+	if err != nil {
+		return sprite.SubTex{}, err
+	}
+	return sprite.SubTex{T: a.Texture, R: f.Frame.Rectangle()}, nil
+}
+
+// Sprite returns the named frame as a SubTex, and its Place.
+func (a *Atlas) Sprite(name string) (sprite.SubTex, *f32.Affine, error) {
+	f, err := a.Frame(name)
+	if err != nil {
+		return sprite.SubTex{}, nil, err
+	}
+	return sprite.SubTex{T: a.Texture, R: f.Frame.Rectangle()}, f.Place(), nil
+}
@@ -0,0 +1,93 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package atlas
+
+import (
+	"bytes"
+	"image"
+	"strings"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	const src = `{
+		"frames": {
+			"arm": {"frame": {"x": 0, "y": 0, "w": 194, "h": 42}},
+			"pad": {"frame": {"x": 194, "y": 0, "w": 100, "h": 286}}
+		},
+		"meta": {"image": "balloon_sheet.png", "size": {"w": 294, "h": 634}}
+	}`
+	d, err := Decode(strings.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := d.Frames["pad"].Frame.Rectangle(), image.Rect(194, 0, 294, 286); got != want {
+		t.Errorf("pad is %v, want %v", got, want)
+	}
+
+	// Encode and decode again.
+	var buf bytes.Buffer
+	if err := d.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	d2, err := Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d2.Frames["arm"] != d.Frames["arm"] || d2.Meta != d.Meta {
+		t.Errorf("round trip: got %+v, want %+v", d2, d)
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	for _, frame := range []string{
+		`{"frame": {"x": 0, "y": 0, "w": 10, "h": 10}, "rotated": true}`,
+		`{"frame": {"x": 0, "y": 0, "w": 0, "h": 10}}`,
+		`{"frame": {"x": 0, "y": 0, "w": 8, "h": 8}, "trimmed": true,
+			"spriteSourceSize": {"x": 1, "y": 1, "w": 8, "h": 7}, "sourceSize": {"w": 10, "h": 10}}`,
+		`{"frame": {"x": 0, "y": 0, "w": 8, "h": 8}, "trimmed": true,
+			"spriteSourceSize": {"x": 3, "y": 1, "w": 8, "h": 8}, "sourceSize": {"w": 10, "h": 10}}`,
+	} {
+		src := `{"frames": {"f": ` + frame + `}}`
+		if _, err := Decode(strings.NewReader(src)); err == nil {
+			t.Errorf("Decode(%s) succeeded, want error", src)
+		}
+	}
+}
+
+func TestPlace(t *testing.T) {
+	const src = `{"frames": {
+		"whole": {"frame": {"x": 0, "y": 0, "w": 10, "h": 10}},
+		"trimmed": {"frame": {"x": 10, "y": 0, "w": 5, "h": 8}, "trimmed": true,
+			"spriteSourceSize": {"x": 2, "y": 1, "w": 5, "h": 8}, "sourceSize": {"w": 10, "h": 10}}
+	}}`
+	d, err := Decode(strings.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := d.Frames["whole"].Place(); p != nil {
+		t.Errorf("untrimmed frame has Place %v, want nil", p)
+	}
+	p := d.Frames["trimmed"].Place()
+	if p == nil {
+		t.Fatal("trimmed frame has no Place")
+	}
+	// The corners of the unit square of the frame, in the unit square
+	// of the original image.
+	for _, c := range []struct{ x, y, wantX, wantY float32 }{
+		{0, 0, 0.2, 0.1},
+		{1, 1, 0.7, 0.9},
+	} {
+		x := p[0][0]*c.x + p[0][1]*c.y + p[0][2]
+		y := p[1][0]*c.x + p[1][1]*c.y + p[1][2]
+		if !near(x, c.wantX) || !near(y, c.wantY) {
+			t.Errorf("Place maps (%g, %g) to (%g, %g), want (%g, %g)", c.x, c.y, x, y, c.wantX, c.wantY)
+		}
+	}
+}
+
+func near(a, b float32) bool {
+	return a-b < 1e-6 && b-a < 1e-6
+}
@@ -0,0 +1,269 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Atlaspack packs a directory of PNG images into sprite sheets.
+//
+// Usage:
+//
+//	atlaspack [flags] dir
+//
+// Each sheet is written as a PNG and an atlas descriptor, for example
+// balloon_sheet.png and balloon_sheet.json. If the images do not fit on
+// one sheet, further sheets are named balloon_sheet1.png, and so on.
+// Frames are named after their files, without the .png extension.
+//
+// The sides of a sheet are powers of two, no larger than -size, which
+// must itself be a power of two. With -trim, the transparent borders of
+// frames are not packed, and the descriptor records where each trimmed
+// frame sits in its image.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/crawshaw/balloon/atlas"
+)
+
+var (
+	out     = flag.String("o", "sheet", "output file name, without extension")
+	maxSize = flag.Int("size", 1024, "maximum width and height of a sheet, a power of two")
+	padding = flag.Int("pad", 2, "transparent pixels around each frame")
+	trim    = flag.Bool("trim", false, "remove transparent borders from frames")
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: atlaspack [flags] dir\n")
+	flag.PrintDefaults()
+	os.Exit(2)
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("atlaspack: ")
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() != 1 {
+		usage()
+	}
+	if *maxSize <= 0 || pow2(*maxSize) != *maxSize {
+		log.Fatalf("-size %d is not a power of two", *maxSize)
+	}
+
+	frames, err := readFrames(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	sheets, err := pack(frames, *maxSize, *padding)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for i, s := range sheets {
+		name := *out
+		if i > 0 {
+			name = fmt.Sprintf("%s%d", *out, i)
+		}
+		if err := s.write(name); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// frame is an image to be packed.
+type frame struct {
+	name string
+	m    image.Image
+	src  image.Rectangle // part of m that is packed
+	pos  image.Point     // position of src on the sheet
+}
+
+func readFrames(dir string) ([]*frame, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var frames []*frame
+	for _, info := range infos {
+		if info.IsDir() || filepath.Ext(info.Name()) != ".png" {
+			continue
+		}
+		m, err := readPNG(filepath.Join(dir, info.Name()))
+		if err != nil {
+			return nil, err
+		}
+		f := &frame{
+			name: strings.TrimSuffix(info.Name(), ".png"),
+			m:    m,
+			src:  m.Bounds(),
+		}
+		if *trim {
+			f.src = opaqueBounds(m)
+		}
+		if f.src.Empty() {
+			return nil, fmt.Errorf("%s: image is empty", info.Name())
+		}
+		frames = append(frames, f)
+	}
+	if len(frames) == 0 {
+		return nil, fmt.Errorf("no PNG images in %s", dir)
+	}
+	return frames, nil
+}
+
+func readPNG(name string) (image.Image, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	m, err := png.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return m, nil
+}
+
+// opaqueBounds returns the smallest rectangle holding all the
+// non-transparent pixels of m.
+func opaqueBounds(m image.Image) image.Rectangle {
+	var r image.Rectangle
+	b := m.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if _, _, _, a := m.At(x, y).RGBA(); a != 0 {
+				r = r.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+	return r
+}
+
+// sheet is a set of frames packed into one image.
+type sheet struct {
+	frames []*frame
+	size   image.Point
+}
+
+// pack places frames on sheets using shelves: frames are sorted by
+// height and placed left to right, starting a new shelf when a row is
+// full and a new sheet when a sheet is full.
+func pack(frames []*frame, maxSize, pad int) ([]*sheet, error) {
+	sort.Sort(byHeight(frames))
+
+	var sheets []*sheet
+	var s *sheet
+	var x, y, shelfH int
+	for _, f := range frames {
+		w, h := f.src.Dx()+pad, f.src.Dy()+pad
+		if w+pad > maxSize || h+pad > maxSize {
+			return nil, fmt.Errorf("%s: %dx%d image does not fit on a %dx%d sheet", f.name, f.src.Dx(), f.src.Dy(), maxSize, maxSize)
+		}
+		if s != nil && x+w > maxSize {
+			// Next shelf.
+			x, y, shelfH = pad, y+shelfH, 0
+		}
+		if s == nil || y+h > maxSize {
+			s = new(sheet)
+			sheets = append(sheets, s)
+			x, y, shelfH = pad, pad, 0
+		}
+		f.pos = image.Point{x, y}
+		s.frames = append(s.frames, f)
+		x += w
+		if h > shelfH {
+			shelfH = h
+		}
+		if x > s.size.X {
+			s.size.X = x
+		}
+		if y+h > s.size.Y {
+			s.size.Y = y + h
+		}
+	}
+	for _, s := range sheets {
+		s.size.X = pow2(s.size.X)
+		s.size.Y = pow2(s.size.Y)
+	}
+	return sheets, nil
+}
+
+type byHeight []*frame
+
+func (a byHeight) Len() int      { return len(a) }
+func (a byHeight) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a byHeight) Less(i, j int) bool {
+	if hi, hj := a[i].src.Dy(), a[j].src.Dy(); hi != hj {
+		return hi > hj
+	}
+	return a[i].name < a[j].name
+}
+
+// pow2 rounds x up to a power of two, for the benefit of GL.
+// As x is at most the -size flag, which is a power of two, so is pow2(x).
+func pow2(x int) int {
+	p := 1
+	for p < x {
+		p *= 2
+	}
+	return p
+}
+
+func (s *sheet) write(name string) error {
+	m := image.NewNRGBA(image.Rectangle{Max: s.size})
+	d := &atlas.Descriptor{
+		Frames: make(map[string]atlas.Frame),
+		Meta: atlas.Meta{
+			Image: filepath.Base(name) + ".png",
+			Size:  atlas.Size{W: s.size.X, H: s.size.Y},
+		},
+	}
+	for _, f := range s.frames {
+		r := image.Rectangle{f.pos, f.pos.Add(f.src.Size())}
+		draw.Draw(m, r, f.m, f.src.Min, draw.Src)
+
+		b := f.m.Bounds()
+		d.Frames[f.name] = atlas.Frame{
+			Frame:   atlas.Rect{X: r.Min.X, Y: r.Min.Y, W: r.Dx(), H: r.Dy()},
+			Trimmed: f.src != b,
+			SpriteSourceSize: atlas.Rect{
+				X: f.src.Min.X - b.Min.X,
+				Y: f.src.Min.Y - b.Min.Y,
+				W: f.src.Dx(),
+				H: f.src.Dy(),
+			},
+			SourceSize: atlas.Size{W: b.Dx(), H: b.Dy()},
+		}
+	}
+
+	pf, err := os.Create(name + ".png")
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(pf, m); err != nil {
+		pf.Close()
+		return err
+	}
+	if err := pf.Close(); err != nil {
+		return err
+	}
+
+	jf, err := os.Create(name + ".json")
+	if err != nil {
+		return err
+	}
+	if err := d.Encode(jf); err != nil {
+		jf.Close()
+		return err
+	}
+	return jf.Close()
+}
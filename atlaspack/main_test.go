@@ -0,0 +1,125 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/crawshaw/balloon/atlas"
+)
+
+func TestPow2(t *testing.T) {
+	for _, tt := range []struct{ x, want int }{
+		{1, 1},
+		{2, 2},
+		{3, 4},
+		{1000, 1024},
+		{1024, 1024},
+	} {
+		if got := pow2(tt.x); got != tt.want {
+			t.Errorf("pow2(%d) = %d, want %d", tt.x, got, tt.want)
+		}
+	}
+}
+
+func TestPack(t *testing.T) {
+	const maxSize, pad = 64, 2
+	var frames []*frame
+	for i := 0; i < 20; i++ {
+		frames = append(frames, &frame{
+			name: fmt.Sprintf("f%02d", i),
+			src:  image.Rect(0, 0, 10+i%3, 12+i%5),
+		})
+	}
+	sheets, err := pack(frames, maxSize, pad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sheets) < 2 {
+		t.Errorf("got %d sheets, want at least 2", len(sheets))
+	}
+	n := 0
+	for i, s := range sheets {
+		if s.size.X > maxSize || s.size.Y > maxSize || pow2(s.size.X) != s.size.X || pow2(s.size.Y) != s.size.Y {
+			t.Errorf("sheet %d: size %v, want powers of two at most %d", i, s.size, maxSize)
+		}
+		bounds := image.Rectangle{Max: s.size}
+		for j, f := range s.frames {
+			r := image.Rectangle{f.pos, f.pos.Add(f.src.Size())}
+			if !r.In(bounds) {
+				t.Errorf("sheet %d: %s at %v is outside %v", i, f.name, r, bounds)
+			}
+			for _, g := range s.frames[:j] {
+				if r.Overlaps(image.Rectangle{g.pos, g.pos.Add(g.src.Size())}) {
+					t.Errorf("sheet %d: %s overlaps %s", i, f.name, g.name)
+				}
+			}
+		}
+		n += len(s.frames)
+	}
+	if n != len(frames) {
+		t.Errorf("packed %d frames, want %d", n, len(frames))
+	}
+}
+
+func TestPackTooBig(t *testing.T) {
+	frames := []*frame{{name: "big", src: image.Rect(0, 0, 64, 10)}}
+	if _, err := pack(frames, 64, 2); err == nil {
+		t.Error("pack succeeded, want error for a frame wider than the sheet")
+	}
+}
+
+func TestTrim(t *testing.T) {
+	m := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	m.Set(2, 3, color.NRGBA{0xff, 0, 0, 0xff})
+	m.Set(5, 8, color.NRGBA{0, 0, 0xff, 0x80})
+	if got, want := opaqueBounds(m), image.Rect(2, 3, 6, 9); got != want {
+		t.Errorf("opaqueBounds = %v, want %v", got, want)
+	}
+
+	dir, err := ioutil.TempDir("", "atlaspack")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	frames := []*frame{
+		{name: "trimmed", m: m, src: opaqueBounds(m)},
+		{name: "whole", m: m, src: m.Bounds()},
+	}
+	sheets, err := pack(frames, 64, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	name := filepath.Join(dir, "sheet")
+	if err := sheets[0].write(name); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(name + ".json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	d, err := atlas.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tr := d.Frames["trimmed"]
+	if !tr.Trimmed || tr.Frame.W != 4 || tr.Frame.H != 6 {
+		t.Errorf("trimmed frame is %+v, want a trimmed 4x6 frame", tr)
+	}
+	if want := (atlas.Rect{X: 2, Y: 3, W: 4, H: 6}); tr.SpriteSourceSize != want || tr.SourceSize != (atlas.Size{W: 10, H: 10}) {
+		t.Errorf("trimmed frame is at %+v in %+v, want %+v in 10x10", tr.SpriteSourceSize, tr.SourceSize, want)
+	}
+	if wh := d.Frames["whole"]; wh.Trimmed || wh.Frame.W != 10 || wh.Frame.H != 10 {
+		t.Errorf("whole frame is %+v, want an untrimmed 10x10 frame", wh)
+	}
+}
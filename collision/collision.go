@@ -0,0 +1,127 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package collision detects overlapping sprites.
+//
+// A sprite is the unit square transformed by its node and the node's
+// ancestors, so in the scene it is a rotated rectangle: a Box.
+// Transforms are found as in package hit.
+package collision
+
+import (
+	"golang.org/x/mobile/geom"
+	"golang.org/x/mobile/sprite"
+	"golang.org/x/mobile/sprite/clock"
+
+	"github.com/crawshaw/balloon/hit"
+)
+
+// Box is a rectangle in scene coordinates, possibly rotated.
+// Its corners are in order around the rectangle.
+type Box [4]geom.Point
+
+// NodeBox returns the Box of the sprite drawn for n at time t.
+// It reports false if the sprite is not visible, for example because
+// it or an ancestor is hidden.
+func NodeBox(n *sprite.Node, t clock.Time) (Box, bool) {
+	m := hit.World(n, t)
+	if m[0][0]*m[1][1]-m[0][1]*m[1][0] == 0 {
+		return Box{}, false
+	}
+	return Box{
+		hit.Apply(&m, geom.Point{0, 0}),
+		hit.Apply(&m, geom.Point{1, 0}),
+		hit.Apply(&m, geom.Point{1, 1}),
+		hit.Apply(&m, geom.Point{0, 1}),
+	}, true
+}
+
+// Bounds returns the axis-aligned bounding rectangle of b.
+func (b *Box) Bounds() geom.Rectangle {
+	r := geom.Rectangle{Min: b[0], Max: b[0]}
+	for _, p := range b[1:] {
+		if p.X < r.Min.X {
+			r.Min.X = p.X
+		}
+		if p.Y < r.Min.Y {
+			r.Min.Y = p.Y
+		}
+		if p.X > r.Max.X {
+			r.Max.X = p.X
+		}
+		if p.Y > r.Max.Y {
+			r.Max.Y = p.Y
+		}
+	}
+	return r
+}
+
+// Overlap reports whether a and b overlap.
+func Overlap(a, b *Box) bool {
+	ra, rb := a.Bounds(), b.Bounds()
+	if ra.Max.X <= rb.Min.X || rb.Max.X <= ra.Min.X || ra.Max.Y <= rb.Min.Y || rb.Max.Y <= ra.Min.Y {
+		return false
+	}
+	// Separating axis test. The axes to try are the normals of
+	// the edges of each box.
+	return !separated(a, b) && !separated(b, a)
+}
+
+// separated reports whether an edge of a separates a from b.
+func separated(a, b *Box) bool {
+	for i := 0; i < 2; i++ {
+		p, q := a[i], a[i+1]
+		nx, ny := -(q.Y - p.Y), q.X-p.X
+		amin, amax := project(a, nx, ny)
+		bmin, bmax := project(b, nx, ny)
+		if amax <= bmin || bmax <= amin {
+			return true
+		}
+	}
+	return false
+}
+
+func project(b *Box, nx, ny geom.Pt) (min, max geom.Pt) {
+	min = b[0].X*nx + b[0].Y*ny
+	max = min
+	for _, p := range b[1:] {
+		d := p.X*nx + p.Y*ny
+		if d < min {
+			min = d
+		}
+		if d > max {
+			max = d
+		}
+	}
+	return min, max
+}
+
+// Pair is two overlapping nodes.
+type Pair struct {
+	A, B *sprite.Node
+}
+
+// Detector reports overlaps between a set of nodes.
+type Detector struct {
+	Nodes []*sprite.Node
+}
+
+// Overlaps returns the pairs of nodes whose sprites overlap at time t.
+// Each Pair has A earlier in Nodes than B.
+func (d *Detector) Overlaps(t clock.Time) []Pair {
+	boxes := make([]Box, len(d.Nodes))
+	visible := make([]bool, len(d.Nodes))
+	for i, n := range d.Nodes {
+		boxes[i], visible[i] = NodeBox(n, t)
+	}
+	var pairs []Pair
+	for i := range d.Nodes {
+		for j := i + 1; j < len(d.Nodes); j++ {
+			if visible[i] && visible[j] && Overlap(&boxes[i], &boxes[j]) {
+				pairs = append(pairs, Pair{d.Nodes[i], d.Nodes[j]})
+			}
+		}
+	}
+	return pairs
+}
@@ -0,0 +1,97 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package collision
+
+import (
+	"testing"
+
+	"golang.org/x/mobile/f32"
+	"golang.org/x/mobile/geom"
+	"golang.org/x/mobile/sprite"
+	"golang.org/x/mobile/sprite/clock"
+)
+
+// diamond is a square of side √2 rotated by 45°, centered on the origin.
+var diamond = Box{{1, 0}, {0, 1}, {-1, 0}, {0, -1}}
+
+// square returns the axis-aligned unit square with top-left corner at x, y.
+func square(x, y geom.Pt) Box {
+	return Box{{x, y}, {x + 1, y}, {x + 1, y + 1}, {x, y + 1}}
+}
+
+func TestOverlap(t *testing.T) {
+	testCases := []struct {
+		name string
+		a, b Box
+		want bool
+	}{
+		{"same", square(0, 0), square(0, 0), true},
+		{"apart", square(0, 0), square(3, 0), false},
+		{"edges touch", square(0, 0), square(1, 0), false},
+		{"corners touch", square(0, 0), square(1, 1), false},
+		{"inside", square(-0.5, -0.5), diamond, true},
+		// The bounds overlap, but the diamond's edge x+y=1 is between them.
+		{"rotated apart", diamond, square(0.6, 0.6), false},
+		{"rotated touch", diamond, square(0.5, 0.5), false},
+		{"rotated overlap", diamond, square(0.4, 0.4), true},
+		{"rotated corners touch", diamond, Box{{2, 0}, {3, 1}, {4, 0}, {3, -1}}, false},
+		{"rotated both", diamond, Box{{0.5, 0}, {1.5, 1}, {2.5, 0}, {1.5, -1}}, true},
+	}
+	for _, tc := range testCases {
+		if got := Overlap(&tc.a, &tc.b); got != tc.want {
+			t.Errorf("%s: Overlap(a, b) = %v, want %v", tc.name, got, tc.want)
+		}
+		if got := Overlap(&tc.b, &tc.a); got != tc.want {
+			t.Errorf("%s: Overlap(b, a) = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestBounds(t *testing.T) {
+	got := diamond.Bounds()
+	want := geom.Rectangle{Min: geom.Point{-1, -1}, Max: geom.Point{1, 1}}
+	if got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+// affine is an Arranger with a fixed transform.
+type affine f32.Affine
+
+func (a *affine) Arrange(e sprite.Engine, n *sprite.Node, t clock.Time) {}
+func (a *affine) AffineAt(t clock.Time) f32.Affine                      { return f32.Affine(*a) }
+
+func node(parent *sprite.Node, m f32.Affine) *sprite.Node {
+	a := affine(m)
+	n := &sprite.Node{Arranger: &a}
+	if parent != nil {
+		parent.AppendChild(n)
+	}
+	return n
+}
+
+func TestOverlaps(t *testing.T) {
+	root := node(nil, f32.Affine{{10, 0, 0}, {0, 10, 0}})
+	a := node(root, f32.Affine{{1, 0, 0}, {0, 1, 0}})
+	b := node(root, f32.Affine{{1, 0, 0.5}, {0, 1, 0.5}})
+	c := node(root, f32.Affine{{1, 0, 2}, {0, 1, 0}})
+	// hidden is scaled to nothing, so overlaps nothing.
+	hidden := node(root, f32.Affine{{0, 0, 0}, {0, 0, 0}})
+
+	d := &Detector{Nodes: []*sprite.Node{a, b, c, hidden}}
+	got := d.Overlaps(0)
+	if len(got) != 1 || got[0] != (Pair{a, b}) {
+		t.Errorf("got %v, want [{a b}]", got)
+	}
+
+	box, ok := NodeBox(c, 0)
+	want := Box{{20, 0}, {30, 0}, {30, 10}, {20, 10}}
+	if !ok || box != want {
+		t.Errorf("NodeBox(c) = %v, %v, want %v, true", box, ok, want)
+	}
+	if _, ok := NodeBox(hidden, 0); ok {
+		t.Errorf("NodeBox(hidden) reported visible")
+	}
+}
@@ -14,11 +14,17 @@ import (
 	"golang.org/x/mobile/sprite/clock"
 
 	"github.com/crawshaw/balloon/animation"
+	"github.com/crawshaw/balloon/collision"
+	"github.com/crawshaw/balloon/physics"
+	"github.com/crawshaw/balloon/scene"
 	"github.com/crawshaw/balloon/text"
 )
 
 const expandTime = 10
 
+// balloonInflate is how much the balloon grows when a gopher is saved.
+const balloonInflate = 4
+
 var game struct {
 	balloon   *animation.Arrangement
 	nextTouch *event.Touch
@@ -27,13 +33,15 @@ var game struct {
 	score     int
 	lives     int
 
-	gophers []*animation.Arrangement
+	rand    *rand.Rand // seeded, so a recorded session replays exactly
+	gophers []*sprite.Node
 
 	dropGopher *animation.Arrangement
+	dropNode   *sprite.Node // node of dropGopher
+	floating   bool         // dropGopher is saved and floating away
 	dropStart  clock.Time
 	dropEnd    clock.Time
 	dropSave   clock.Time
-	dropSaveY  geom.Pt
 }
 
 const initialPause = 180
@@ -53,9 +61,9 @@ func startGame() {
 			},
 		}
 		eng.Register(gopher)
-		gameScene.AppendChild(gopher)
+		gameScene.Node.AppendChild(gopher)
 
-		game.gophers = append(game.gophers, gopher.Arranger.(*animation.Arrangement))
+		game.gophers = append(game.gophers, gopher)
 	}
 
 	addGopher(36, sheet.gopherSwim)
@@ -63,10 +71,10 @@ func startGame() {
 
 	b := new(sprite.Node)
 	eng.Register(b)
-	gameScene.AppendChild(b)
+	gameScene.Node.AppendChild(b)
 	game.balloon = &animation.Arrangement{
-		Pivot:  geom.Point{X: 6, Y: 72},
-		Size:   &geom.Point{X: 24, Y: 72},
+		Pivot:  geom.Point{X: 6.0 / balloonInflate, Y: 72.0 / balloonInflate},
+		Size:   &geom.Point{X: 24.0 / balloonInflate, Y: 72.0 / balloonInflate},
 		SubTex: sheet.balloon,
 		Hidden: true,
 	}
@@ -74,11 +82,8 @@ func startGame() {
 }
 
 func updateGame(t clock.Time) {
-	if scene == menuScene {
-		return
-	}
 	if game.lives == 0 {
-		scene = overScene
+		scenes.Replace(t, overScene, scene.Transition{Effect: scene.Fade, Duration: 40})
 		return
 	}
 
@@ -88,16 +93,47 @@ func updateGame(t clock.Time) {
 		g := game.dropGopher
 		if game.dropSave > 0 && t > game.dropSave {
 			// float away
-			tween := clock.Linear(game.dropSave, game.dropEnd, t)
+			if !game.floating {
+				game.floating = true
+				// Rise from here to off the top of the screen by dropEnd.
+				dist := float32(g.Offset.Y + g.Size.Y*2)
+				d := float32(game.dropEnd - t)
+				if d < 1 {
+					d = 1
+				}
+				g.Physics = &physics.Physics{
+					Velocity:     geom.Point{X: geom.Pt(game.rand.Float32()-0.5) / 2},
+					Acceleration: geom.Point{Y: geom.Pt(-2 * dist / (d * d))},
+				}
+			}
 			b := game.balloon
-			g.Offset.Y = -g.Size.Y*2 + (game.dropSaveY+g.Size.Y*2)*geom.Pt(1-tween)
 			b.Offset.X = g.Offset.X
 			b.Offset.Y = g.Offset.Y
 			b.Hidden = false
-		} else {
-			// fall to doom
-			tween := clock.Linear(game.dropStart, game.dropEnd, t)
-			g.Offset.Y = (geom.Height + g.Size.Y*2) * geom.Pt(tween)
+		}
+	}
+
+	if game.dropGopher != nil && game.dropSave == 0 && game.scissor.firing {
+		// Save the gopher if the balloon on the arm touches it.
+		a, aok := collision.NodeBox(game.scissor.balloon, t)
+		g, gok := collision.NodeBox(game.dropNode, t)
+		if aok && gok && collision.Overlap(&a, &g) {
+			game.dropSave = t
+			game.score++
+
+			// Inflate the balloon, and fade it as it floats away.
+			game.balloon.T0 = game.dropSave
+			game.balloon.T1 = game.dropEnd
+			game.balloon.Transform = animation.Transform{
+				Transformer: animation.Parallel{
+					animation.Clip{
+						End:         0.2,
+						Tween:       clock.EaseOut,
+						Transformer: animation.Scale(balloonInflate),
+					},
+					animation.Delay(0.7, animation.Fade(1)),
+				},
+			}
 		}
 	}
 
@@ -108,9 +144,13 @@ func updateGame(t clock.Time) {
 				game.lives--
 			}
 			game.dropSave = 0
+			game.floating = false
 			game.dropGopher.Offset.Y = -game.dropGopher.Size.Y
+			game.dropGopher.Physics = nil
 			game.dropGopher = nil
+			game.dropNode = nil
 			game.balloon.Hidden = true
+			game.balloon.Transform = animation.Transform{}
 		}
 
 		duration := 80
@@ -120,43 +160,24 @@ func updateGame(t clock.Time) {
 		game.dropStart = t
 		game.dropEnd = t + clock.Time(duration)
 
-		num := rand.Intn(len(game.gophers))
-		game.dropGopher = game.gophers[num]
+		num := game.rand.Intn(len(game.gophers))
+		game.dropNode = game.gophers[num]
+		g := game.dropNode.Arranger.(*animation.Arrangement)
+		game.dropGopher = g
 		minX := minX + 5 // you have to move the balloon to score
-		game.dropGopher.Offset.X = minX + (maxX-minX)*geom.Pt(rand.Float32())
-	}
-
-	if game.nextTouch != nil && game.scissor.a.Current == "ready" {
-		y := game.nextTouch.Loc.Y
-
-		if t < game.dropEnd {
-			// check for intersection of balloon and gopher
-			g := game.dropGopher
-			startY := game.dropStart
-			startX := t
-			durationY := game.dropEnd - game.dropStart
-			durationX := expandTime
-
-			x := g.Offset.X
-			minY := geom.Pt(0)
-			maxY := geom.Height + g.Size.Y*2
+		g.Offset.X = minX + (maxX-minX)*geom.Pt(game.rand.Float32())
 
-			gopherT := int((y-minY)/(maxY-minY)*geom.Pt(durationY)) + int(startY)
-			balloonT := int((x-minX)/(maxX-minX)*geom.Pt(durationX)) + int(startX)
-
-			diff := gopherT - balloonT
-			if diff < 0 {
-				diff = -diff
-			}
-			if diff < 4 {
-				game.dropSave = clock.Time(gopherT)
-				game.dropSaveY = y
-				game.score++
-			}
+		// Fall under gravity to off the bottom of the screen by dropEnd.
+		fall := float32(geom.Height + g.Size.Y*2)
+		g.Offset.Y = 0
+		g.Physics = &physics.Physics{
+			Acceleration: geom.Point{Y: geom.Pt(2 * fall / float32(duration*duration))},
 		}
+	}
 
-		game.scissor.arrangement.Offset.Y = y
-		game.scissor.a.Transition(t, "expanding")
+	if game.nextTouch != nil && game.scissor.ready {
+		game.scissor.arrangement.Offset.Y = game.nextTouch.Loc.Y
+		game.scissor.a.Trigger(t, "fire")
 	}
 	game.nextTouch = nil
 
@@ -0,0 +1,178 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package harness renders sprite scenes without a window, for tests.
+//
+// A Harness draws with the portable engine into an image, on a clock
+// that only moves when it is stepped. Frames can be compared with golden
+// PNG files:
+//
+//	h := harness.New(160, 240, 1)
+//	root := buildScene(h.Engine)
+//	h.Step(30)
+//	if err := harness.CheckGolden(h.Render(root), "testdata/scene.png", *update); err != nil {
+//		t.Error(err)
+//	}
+package harness
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"os"
+	"strings"
+
+	"golang.org/x/mobile/geom"
+	"golang.org/x/mobile/sprite"
+	"golang.org/x/mobile/sprite/clock"
+	"golang.org/x/mobile/sprite/portable"
+
+	"github.com/crawshaw/balloon/tick"
+)
+
+// Tolerance is the largest difference in any color channel between
+// two pixels that CheckGolden treats as equal. It allows for rounding
+// differences between platforms.
+var Tolerance uint8 = 2
+
+// Harness is a headless sprite engine and clock.
+type Harness struct {
+	Engine     sprite.Engine
+	Clock      tick.Manual // time of the next Render
+	Background color.Color // drawn under each frame, white by default
+
+	dst *image.RGBA
+}
+
+// New returns a Harness with a screen of the given size in points.
+//
+// It sets geom.Width, geom.Height and geom.PixelsPerPt, as the app
+// package does on a device.
+func New(width, height geom.Pt, pixelsPerPt float32) *Harness {
+	geom.Width, geom.Height, geom.PixelsPerPt = width, height, pixelsPerPt
+	w := int(math.Ceil(float64(float32(width) * pixelsPerPt)))
+	h := int(math.Ceil(float64(float32(height) * pixelsPerPt)))
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	return &Harness{
+		Engine:     portable.Engine(dst),
+		Background: color.White,
+		dst:        dst,
+	}
+}
+
+// Step advances the clock by d ticks.
+func (h *Harness) Step(d clock.Time) {
+	h.Clock.Advance(d)
+}
+
+// Render draws the scene rooted at root at the current time, and
+// returns a copy of the frame.
+func (h *Harness) Render(root *sprite.Node) *image.RGBA {
+	draw.Draw(h.dst, h.dst.Bounds(), image.NewUniform(h.Background), image.Point{}, draw.Src)
+	h.Engine.Render(root, h.Clock.Now())
+	m := image.NewRGBA(h.dst.Bounds())
+	copy(m.Pix, h.dst.Pix)
+	return m
+}
+
+// Frames renders n frames of root, stepping the clock by d before each
+// frame after the first. If update is non-nil, it is called with the
+// time of each frame before the frame is drawn.
+func (h *Harness) Frames(root *sprite.Node, n int, d clock.Time, update func(t clock.Time)) []*image.RGBA {
+	frames := make([]*image.RGBA, n)
+	for i := range frames {
+		if i > 0 {
+			h.Step(d)
+		}
+		if update != nil {
+			update(h.Clock.Now())
+		}
+		frames[i] = h.Render(root)
+	}
+	return frames
+}
+
+// CheckGolden compares m with the golden PNG file name.
+//
+// If update is true, m is written to name instead. If the images differ,
+// m is written next to the golden file with the suffix _got.png for
+// inspection.
+func CheckGolden(m image.Image, name string, update bool) error {
+	if update {
+		return writePNG(name, m)
+	}
+	golden, err := readPNG(name)
+	if err != nil {
+		return err
+	}
+	if err := compare(m, golden); err != nil {
+		got := strings.TrimSuffix(name, ".png") + "_got.png"
+		if werr := writePNG(got, m); werr != nil {
+			return fmt.Errorf("harness: %s: %v (writing %s: %v)", name, err, got, werr)
+		}
+		return fmt.Errorf("harness: %s: %v (got %s)", name, err, got)
+	}
+	return nil
+}
+
+// compare returns an error describing how got differs from want.
+func compare(got, want image.Image) error {
+	gb, wb := got.Bounds(), want.Bounds()
+	if gb.Size() != wb.Size() {
+		return fmt.Errorf("image is %v, want %v", gb.Size(), wb.Size())
+	}
+	n := 0
+	var first image.Point
+	for y := 0; y < gb.Dy(); y++ {
+		for x := 0; x < gb.Dx(); x++ {
+			g := color.NRGBAModel.Convert(got.At(gb.Min.X+x, gb.Min.Y+y)).(color.NRGBA)
+			w := color.NRGBAModel.Convert(want.At(wb.Min.X+x, wb.Min.Y+y)).(color.NRGBA)
+			if differ(g.R, w.R) || differ(g.G, w.G) || differ(g.B, w.B) || differ(g.A, w.A) {
+				if n == 0 {
+					first = image.Point{x, y}
+				}
+				n++
+			}
+		}
+	}
+	if n > 0 {
+		return fmt.Errorf("%d pixels differ, first at %v", n, first)
+	}
+	return nil
+}
+
+func differ(a, b uint8) bool {
+	if a > b {
+		return a-b > Tolerance
+	}
+	return b-a > Tolerance
+}
+
+func readPNG(name string) (image.Image, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	m, err := png.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("harness: %s: %v", name, err)
+	}
+	return m, nil
+}
+
+func writePNG(name string, m image.Image) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, m); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
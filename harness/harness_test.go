@@ -0,0 +1,135 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package harness
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"code.google.com/p/freetype-go/freetype"
+	"golang.org/x/mobile/geom"
+	"golang.org/x/mobile/sprite"
+
+	"github.com/crawshaw/balloon/animation"
+	"github.com/crawshaw/balloon/text"
+)
+
+var update = flag.Bool("update", false, "write the golden files in testdata")
+
+// testScene returns a red box that an Animation moves right and then
+// spins, over a line of text. testdata/Go-Regular.ttf is the Go font,
+// from golang.org/x/image/font/gofont, under the license in
+// testdata/Go-Regular.LICENSE.
+func testScene(t *testing.T, e sprite.Engine) *sprite.Node {
+	b, err := ioutil.ReadFile("testdata/Go-Regular.ttf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	font, err := freetype.ParseFont(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	red := image.NewRGBA(image.Rect(0, 0, 1, 1))
+	red.Set(0, 0, color.RGBA{0xff, 0, 0, 0xff})
+	tex, err := e.LoadTexture(red)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root := &sprite.Node{Arranger: &animation.Animation{
+		Current: "move",
+		States: map[string]animation.State{
+			"move": {
+				Duration: 30,
+				Next:     "spin",
+				Transforms: map[string]animation.Transform{
+					"box": {Transformer: animation.Move{X: 40}},
+				},
+			},
+			"spin": {
+				Duration: 30,
+				Transforms: map[string]animation.Transform{
+					"box": {Transformer: animation.Rotate(1.5)},
+				},
+			},
+		},
+	}}
+	box := &sprite.Node{Arranger: &animation.Arrangement{
+		Name:   "box",
+		Offset: geom.Point{X: 12, Y: 12},
+		Pivot:  geom.Point{X: 8, Y: 8},
+		Size:   &geom.Point{X: 16, Y: 16},
+		SubTex: sprite.SubTex{T: tex, R: red.Bounds()},
+	}}
+	label := &sprite.Node{Arranger: &animation.Arrangement{
+		Offset: geom.Point{X: 4, Y: 40},
+	}}
+	str := &sprite.Node{Arranger: &text.String{
+		Text:  "Gopher",
+		Size:  10,
+		Color: color.RGBA{0, 0, 0x80, 0xff},
+		Font:  font,
+	}}
+	for _, n := range []*sprite.Node{root, box, label, str} {
+		e.Register(n)
+	}
+	root.AppendChild(box)
+	root.AppendChild(label)
+	label.AppendChild(str)
+	return root
+}
+
+func TestGolden(t *testing.T) {
+	h := New(64, 48, 1)
+	root := testScene(t, h.Engine)
+	for i, m := range h.Frames(root, 4, 20, nil) {
+		name := fmt.Sprintf("testdata/scene%d.png", i)
+		err := CheckGolden(m, name, *update)
+		if os.IsNotExist(err) {
+			t.Errorf("%v: run go test -update to create it", err)
+		} else if err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestCheckGolden(t *testing.T) {
+	dir, err := ioutil.TempDir("", "harness")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	name := dir + "/golden.png"
+
+	m := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	draw.Draw(m, m.Bounds(), image.Black, image.Point{}, draw.Src)
+	if err := CheckGolden(m, name, true); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if err := CheckGolden(m, name, false); err != nil {
+		t.Errorf("same image: %v", err)
+	}
+	m.Set(1, 2, color.RGBA{Tolerance, 0, 0, 0xff})
+	if err := CheckGolden(m, name, false); err != nil {
+		t.Errorf("image within Tolerance: %v", err)
+	}
+	m.Set(1, 2, color.RGBA{0xff, 0, 0, 0xff})
+	if err := CheckGolden(m, name, false); err == nil {
+		t.Error("different image: no error")
+	}
+	if _, err := os.Stat(dir + "/golden_got.png"); err != nil {
+		t.Errorf("different image: %v", err)
+	}
+	if err := CheckGolden(image.NewRGBA(image.Rect(0, 0, 4, 5)), name, false); err == nil {
+		t.Error("different size: no error")
+	}
+}
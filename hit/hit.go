@@ -0,0 +1,111 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package hit maps between scene coordinates and the coordinates of a
+// sprite.Node, and finds the node under a point.
+//
+// A node's local space is the unit square its sprite is drawn into.
+// Its world transform maps local space to the scene, and is the product
+// of the transforms of the node and its ancestors.
+package hit
+
+import (
+	"golang.org/x/mobile/f32"
+	"golang.org/x/mobile/geom"
+	"golang.org/x/mobile/sprite"
+	"golang.org/x/mobile/sprite/clock"
+)
+
+// Affiner is implemented by Arrangers that report the transform they
+// give their node at time t, such as *animation.Arrangement.
+//
+// Nodes whose Arranger is not an Affiner are treated as untransformed.
+type Affiner interface {
+	AffineAt(t clock.Time) f32.Affine
+}
+
+// World returns the transform from the local space of n to the scene
+// at time t.
+func World(n *sprite.Node, t clock.Time) f32.Affine {
+	var m f32.Affine
+	m.Identity()
+	for ; n != nil; n = n.Parent {
+		if a, ok := n.Arranger.(Affiner); ok {
+			p := a.AffineAt(t)
+			m.Mul(&p, &m)
+		}
+	}
+	return m
+}
+
+// Apply returns p transformed by m.
+func Apply(m *f32.Affine, p geom.Point) geom.Point {
+	x, y := float32(p.X), float32(p.Y)
+	return geom.Point{
+		X: geom.Pt(m[0][0]*x + m[0][1]*y + m[0][2]),
+		Y: geom.Pt(m[1][0]*x + m[1][1]*y + m[1][2]),
+	}
+}
+
+// Local maps the scene point p into the local space of n at time t.
+// It reports false if n is not visible, so has no local space.
+func Local(n *sprite.Node, t clock.Time, p geom.Point) (geom.Point, bool) {
+	m := World(n, t)
+	if m[0][0]*m[1][1]-m[0][1]*m[1][0] == 0 {
+		return geom.Point{}, false
+	}
+	var inv f32.Affine
+	inv.Inverse(&m)
+	return Apply(&inv, p), true
+}
+
+// Contains reports whether the sprite of n covers the scene point p
+// at time t.
+func Contains(n *sprite.Node, t clock.Time, p geom.Point) bool {
+	l, ok := Local(n, t, p)
+	return ok && 0 <= l.X && l.X < 1 && 0 <= l.Y && l.Y < 1
+}
+
+// Test returns the topmost node in the tree rooted at root whose sprite
+// covers the scene point p at time t, or nil if there is none.
+//
+// Nodes are drawn parent first, then children in order, so a later node
+// is above an earlier one. If match is non-nil, only nodes for which it
+// returns true are considered.
+func Test(root *sprite.Node, t clock.Time, p geom.Point, match func(*sprite.Node) bool) *sprite.Node {
+	var m f32.Affine
+	m.Identity()
+	if root.Parent != nil {
+		m = World(root.Parent, t)
+	}
+	return test(root, &m, t, p, match)
+}
+
+// test is Test with m, the world transform of the parent of n.
+func test(n *sprite.Node, m *f32.Affine, t clock.Time, p geom.Point, match func(*sprite.Node) bool) *sprite.Node {
+	w := *m
+	if a, ok := n.Arranger.(Affiner); ok {
+		q := a.AffineAt(t)
+		w.Mul(m, &q)
+	}
+	if w[0][0]*w[1][1]-w[0][1]*w[1][0] == 0 {
+		// n is hidden, and so are its children.
+		return nil
+	}
+	for c := n.LastChild; c != nil; c = c.PrevSibling {
+		if h := test(c, &w, t, p, match); h != nil {
+			return h
+		}
+	}
+	if match != nil && !match(n) {
+		return nil
+	}
+	var inv f32.Affine
+	inv.Inverse(&w)
+	l := Apply(&inv, p)
+	if 0 <= l.X && l.X < 1 && 0 <= l.Y && l.Y < 1 {
+		return n
+	}
+	return nil
+}
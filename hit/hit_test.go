@@ -0,0 +1,108 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hit
+
+import (
+	"testing"
+
+	"golang.org/x/mobile/f32"
+	"golang.org/x/mobile/geom"
+	"golang.org/x/mobile/sprite"
+	"golang.org/x/mobile/sprite/clock"
+)
+
+// affine is an Arranger with a fixed transform.
+type affine f32.Affine
+
+func (a *affine) Arrange(e sprite.Engine, n *sprite.Node, t clock.Time) {}
+func (a *affine) AffineAt(t clock.Time) f32.Affine                      { return f32.Affine(*a) }
+
+var hidden = f32.Affine{{0, 0, 0}, {0, 0, 0}}
+
+func node(parent *sprite.Node, m f32.Affine) *sprite.Node {
+	a := affine(m)
+	n := &sprite.Node{Arranger: &a}
+	if parent != nil {
+		parent.AppendChild(n)
+	}
+	return n
+}
+
+func TestWorld(t *testing.T) {
+	root := node(nil, f32.Affine{{10, 0, 0}, {0, 10, 0}})
+	// A plain node is untransformed.
+	mid := &sprite.Node{}
+	root.AppendChild(mid)
+	// The child is translated in its parent's space, so by 10pt.
+	child := node(mid, f32.Affine{{1, 0, 1}, {0, 2, 0}})
+
+	m := World(child, 0)
+	if got, want := Apply(&m, geom.Point{0, 0}), (geom.Point{10, 0}); got != want {
+		t.Errorf("World: origin at %v, want %v", got, want)
+	}
+	if got, want := Apply(&m, geom.Point{1, 1}), (geom.Point{20, 20}); got != want {
+		t.Errorf("World: (1, 1) at %v, want %v", got, want)
+	}
+
+	l, ok := Local(child, 0, geom.Point{15, 5})
+	if want := (geom.Point{0.5, 0.25}); !ok || l != want {
+		t.Errorf("Local = %v, %v, want %v, true", l, ok, want)
+	}
+	if !Contains(child, 0, geom.Point{15, 5}) {
+		t.Errorf("Contains(15, 5) = false, want true")
+	}
+	if Contains(child, 0, geom.Point{5, 5}) {
+		t.Errorf("Contains(5, 5) = true, want false")
+	}
+
+	h := node(root, hidden)
+	hc := node(h, f32.Affine{{1, 0, 0}, {0, 1, 0}})
+	for _, n := range []*sprite.Node{h, hc} {
+		if _, ok := Local(n, 0, geom.Point{0, 0}); ok {
+			t.Errorf("Local of hidden node reported visible")
+		}
+	}
+}
+
+func TestTest(t *testing.T) {
+	root := node(nil, f32.Affine{{100, 0, 0}, {0, 100, 0}})
+	// a and b overlap on x in [50, 100), y in [0, 50), and b is drawn above a.
+	a := node(root, f32.Affine{{1, 0, 0}, {0, 0.5, 0}})
+	b := node(root, f32.Affine{{1, 0, 0.5}, {0, 1, 0}})
+	// ac is a child of a, above a but below b.
+	ac := node(a, f32.Affine{{0.5, 0, 0.25}, {0, 0.5, 0.25}})
+	// h is hidden, and so is its child, though both are on top.
+	h := node(root, hidden)
+	hc := node(h, f32.Affine{{1, 0, 0}, {0, 1, 0}})
+
+	names := map[*sprite.Node]string{nil: "nil", root: "root", a: "a", b: "b", ac: "ac", h: "h", hc: "hc"}
+	notB := func(n *sprite.Node) bool { return n != b }
+	testCases := []struct {
+		name  string
+		root  *sprite.Node
+		p     geom.Point
+		match func(*sprite.Node) bool
+		want  *sprite.Node
+	}{
+		{"root", root, geom.Point{5, 75}, nil, root},
+		{"a", root, geom.Point{10, 10}, nil, a},
+		{"b above a", root, geom.Point{75, 25}, nil, b},
+		{"b only", root, geom.Point{125, 50}, nil, b},
+		{"child above parent", root, geom.Point{40, 20}, nil, ac},
+		{"b above child", root, geom.Point{60, 20}, nil, b},
+		{"miss", root, geom.Point{175, 150}, nil, nil},
+		{"match skips b", root, geom.Point{60, 20}, notB, ac},
+		{"match skips to a", root, geom.Point{75, 45}, notB, a},
+		{"match none", root, geom.Point{40, 20}, func(*sprite.Node) bool { return false }, nil},
+		{"subtree in parent space", a, geom.Point{40, 20}, nil, ac},
+		{"subtree misses sibling", a, geom.Point{125, 50}, nil, nil},
+		{"hidden subtree", h, geom.Point{10, 10}, nil, nil},
+	}
+	for _, tc := range testCases {
+		if got := Test(tc.root, 0, tc.p, tc.match); got != tc.want {
+			t.Errorf("%s: Test = %s, want %s", tc.name, names[got], names[tc.want])
+		}
+	}
+}
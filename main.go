@@ -11,7 +11,6 @@ import (
 	"log"
 	"math"
 	"sync"
-	"time"
 
 	"golang.org/x/mobile/app"
 	"golang.org/x/mobile/app/debug"
@@ -26,15 +25,11 @@ var fb struct {
 }
 
 func fbinit() {
-	start = time.Now()
 	toPx := func(x geom.Pt) int { return int(math.Ceil(float64(geom.Pt(x).Px()))) }
 	fb.Image = glutil.NewImage(toPx(geom.Width), toPx(geom.Height))
 	eng = portable.Engine(fb.Image.RGBA)
 
 	timerInit()
-	menuSceneInit()
-	gameSceneInit()
-	scene = menuScene
 }
 
 func main() {
@@ -52,9 +47,8 @@ func drawWindow() {
 	for i := range fb.Image.RGBA.Pix {
 		fb.Image.RGBA.Pix[i] = 0xff // white background
 	}
-	t := now()
-	updateGame(t)
-	eng.Render(scene, t)
+	t := frame()
+	eng.Render(scenes.Node, t)
 	fb.Upload()
 	fb.Draw(
 		geom.Point{},
@@ -21,9 +21,6 @@ var once sync.Once
 func glinit() {
 	eng = glsprite.Engine()
 	timerInit()
-	menuSceneInit()
-	gameSceneInit()
-	scene = menuScene
 }
 
 func main() {
@@ -42,9 +39,8 @@ func drawWindow() {
 	gl.Enable(gl.BLEND)
 	gl.ClearColor(1, 1, 1, 1)
 	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
-	t := now()
-	updateGame(t)
-	eng.Render(scene, t)
+	t := frame()
+	eng.Render(scenes.Node, t)
 
 	debug.DrawFPS()
 }
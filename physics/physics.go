@@ -0,0 +1,114 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package physics implements simple 2D motion and collision.
+//
+// Motion is integrated once per clock tick, so it is the same regardless
+// of frame rate. Distances are in geom.Pt and times in clock ticks.
+package physics
+
+import (
+	"math"
+
+	"golang.org/x/mobile/geom"
+	"golang.org/x/mobile/sprite/clock"
+)
+
+// Gravity is a downwards acceleration for falling bodies, in pt per tick².
+var Gravity = geom.Point{Y: 0.1}
+
+// Physics is the motion of a body.
+type Physics struct {
+	Velocity     geom.Point // pt per tick
+	Acceleration geom.Point // pt per tick², such as Gravity or buoyancy
+	Drag         float32    // fraction of velocity lost each tick
+	Collider     Collider   // optional shape of the body
+
+	last    clock.Time
+	started bool
+}
+
+// Step moves pos from the time of the last Step to t.
+//
+// The first Step only records t.
+func (p *Physics) Step(pos *geom.Point, t clock.Time) {
+	if !p.started || t < p.last {
+		p.last = t
+		p.started = true
+		return
+	}
+	keep := geom.Pt(1 - p.Drag)
+	for ; p.last < t; p.last++ {
+		p.Velocity.X = (p.Velocity.X + p.Acceleration.X) * keep
+		p.Velocity.Y = (p.Velocity.Y + p.Acceleration.Y) * keep
+		pos.X += p.Velocity.X
+		pos.Y += p.Velocity.Y
+	}
+}
+
+// Collider is the shape of a body, relative to its position.
+type Collider interface {
+	// Bounds is the bounding box of the shape of a body at pos.
+	Bounds(pos geom.Point) geom.Rectangle
+}
+
+// Box is an axis-aligned bounding box.
+type Box struct {
+	Min, Max geom.Point
+}
+
+func (b Box) Bounds(pos geom.Point) geom.Rectangle {
+	return geom.Rectangle{
+		Min: geom.Point{X: pos.X + b.Min.X, Y: pos.Y + b.Min.Y},
+		Max: geom.Point{X: pos.X + b.Max.X, Y: pos.Y + b.Max.Y},
+	}
+}
+
+// Circle is a circle.
+type Circle struct {
+	Center geom.Point
+	Radius geom.Pt
+}
+
+func (c Circle) Bounds(pos geom.Point) geom.Rectangle {
+	x, y := pos.X+c.Center.X, pos.Y+c.Center.Y
+	return geom.Rectangle{
+		Min: geom.Point{X: x - c.Radius, Y: y - c.Radius},
+		Max: geom.Point{X: x + c.Radius, Y: y + c.Radius},
+	}
+}
+
+// Overlap reports whether the collider a at position pa overlaps the
+// collider b at position pb.
+//
+// Colliders other than Box and Circle are compared by their Bounds.
+func Overlap(a Collider, pa geom.Point, b Collider, pb geom.Point) bool {
+	ca, aIsCircle := a.(Circle)
+	cb, bIsCircle := b.(Circle)
+	switch {
+	case aIsCircle && bIsCircle:
+		dx := pa.X + ca.Center.X - pb.X - cb.Center.X
+		dy := pa.Y + ca.Center.Y - pb.Y - cb.Center.Y
+		r := ca.Radius + cb.Radius
+		return dx*dx+dy*dy < r*r
+	case aIsCircle:
+		return circleRect(ca, pa, b.Bounds(pb))
+	case bIsCircle:
+		return circleRect(cb, pb, a.Bounds(pa))
+	}
+	return intersects(a.Bounds(pa), b.Bounds(pb))
+}
+
+func circleRect(c Circle, pos geom.Point, r geom.Rectangle) bool {
+	x, y := pos.X+c.Center.X, pos.Y+c.Center.Y
+	// Distance from the center to the closest point of r.
+	dx := x - geom.Pt(math.Max(float64(r.Min.X), math.Min(float64(x), float64(r.Max.X))))
+	dy := y - geom.Pt(math.Max(float64(r.Min.Y), math.Min(float64(y), float64(r.Max.Y))))
+	return dx*dx+dy*dy < c.Radius*c.Radius
+}
+
+func intersects(a, b geom.Rectangle) bool {
+	return a.Min.X < b.Max.X && b.Min.X < a.Max.X &&
+		a.Min.Y < b.Max.Y && b.Min.Y < a.Max.Y
+}
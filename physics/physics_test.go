@@ -0,0 +1,81 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package physics
+
+import (
+	"testing"
+
+	"golang.org/x/mobile/geom"
+	"golang.org/x/mobile/sprite/clock"
+)
+
+func TestStep(t *testing.T) {
+	testCases := []struct {
+		name  string
+		p     Physics
+		ticks clock.Time
+		want  geom.Point
+	}{
+		{"still", Physics{}, 10, geom.Point{}},
+		{"velocity", Physics{Velocity: geom.Point{1, -2}}, 3, geom.Point{3, -6}},
+		// v is 1, 2, 3.
+		{"accelerate", Physics{Acceleration: geom.Point{0, 1}}, 3, geom.Point{0, 6}},
+		// v is 0.5, 0.25, 0.125.
+		{"drag", Physics{Velocity: geom.Point{1, 0}, Drag: 0.5}, 3, geom.Point{0.875, 0}},
+		// v is (0+2)/2 = 1, (1+2)/2 = 1.5, (1.5+2)/2 = 1.75.
+		{"drag and accelerate", Physics{Acceleration: geom.Point{2, 0}, Drag: 0.5}, 3, geom.Point{4.25, 0}},
+	}
+	for _, tc := range testCases {
+		p := tc.p
+		pos := geom.Point{}
+		p.Step(&pos, 100)
+		if pos != (geom.Point{}) {
+			t.Errorf("%s: first Step moved to %v", tc.name, pos)
+		}
+		p.Step(&pos, 100+tc.ticks)
+		if !near(pos, tc.want) {
+			t.Errorf("%s: after %d ticks at %v, want %v", tc.name, tc.ticks, pos, tc.want)
+		}
+	}
+}
+
+// TestStepSplit checks that stepping in several calls moves as far as
+// stepping once, so motion does not depend on the frame rate.
+func TestStepSplit(t *testing.T) {
+	a := Physics{Velocity: geom.Point{3, 1}, Acceleration: Gravity, Drag: 0.1}
+	b := a
+	var pa, pb geom.Point
+	a.Step(&pa, 0)
+	a.Step(&pa, 12)
+	b.Step(&pb, 0)
+	for _, tt := range []clock.Time{1, 2, 2, 7, 12} {
+		b.Step(&pb, tt)
+	}
+	if !near(pa, pb) {
+		t.Errorf("one Step at %v, several at %v", pa, pb)
+	}
+}
+
+// TestStepBackwards checks that a clock going backwards restarts
+// the motion rather than moving the body.
+func TestStepBackwards(t *testing.T) {
+	p := Physics{Velocity: geom.Point{1, 0}}
+	var pos geom.Point
+	p.Step(&pos, 10)
+	p.Step(&pos, 5)
+	if pos != (geom.Point{}) {
+		t.Errorf("Step back in time moved to %v", pos)
+	}
+	p.Step(&pos, 7)
+	if want := (geom.Point{2, 0}); !near(pos, want) {
+		t.Errorf("got %v, want %v", pos, want)
+	}
+}
+
+func near(a, b geom.Point) bool {
+	const eps = 1e-4
+	dx, dy := a.X-b.X, a.Y-b.Y
+	return -eps < dx && dx < eps && -eps < dy && dy < eps
+}
@@ -183,6 +183,9 @@ func (p *printer) print(x reflect.Value) {
 		} else if ar, ok := ptr.(*animation.Arrangement); ok {
 			p.printf("animation.Arrangement {\n")
 			p.indent++
+			if ar.Name != "" {
+				p.printf("Name:       %q\n", ar.Name)
+			}
 			if ar.Offset.X != 0 || ar.Offset.Y != 0 {
 				p.printf("Offset:     %s\n", ar.Offset)
 			}
@@ -198,6 +201,15 @@ func (p *printer) print(x reflect.Value) {
 			if ar.SubTex.T != nil {
 				p.printf("SubTex:    %d\n", ar.SubTex)
 			}
+			if ar.Flipbook != nil {
+				p.printf("Flipbook:   %d frames\n", len(ar.Flipbook.Frames))
+			}
+			if ar.Tint != nil {
+				p.printf("Tint:       %v\n", ar.Tint)
+			}
+			if ar.Fade != 0 {
+				p.printf("Fade:       %g\n", ar.Fade)
+			}
 			if ar.Transform.Transformer != nil {
 				p.printf("Transform:")
 				p.print(reflect.ValueOf(ar.Transform))
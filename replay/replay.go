@@ -0,0 +1,173 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package replay records and replays game input.
+//
+// A Recording holds the time of every frame, every touch and the frame
+// it was handled in, and the seed of the game's random source. A game
+// that takes its time, touches and random numbers only from these
+// reproduces a recorded session exactly.
+//
+// Touches are replayed by frame, not by time, as consecutive frames can
+// have the same time.
+//
+// Recordings are stored as JSON:
+//
+//	{
+//		"seed": 1416531337,
+//		"frames": [0, 1, 3, 4],
+//		"touches": [{"frame": 2, "type": 0, "x": 52.5, "y": 140}]
+//	}
+package replay
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"golang.org/x/mobile/event"
+	"golang.org/x/mobile/geom"
+	"golang.org/x/mobile/sprite/clock"
+
+	"github.com/crawshaw/balloon/tick"
+)
+
+// Touch is a touch and the frame that handled it.
+type Touch struct {
+	Frame int // index in Recording.Frames
+	Touch event.Touch
+}
+
+// Recording is a recorded session.
+type Recording struct {
+	Seed    int64
+	Frames  []clock.Time
+	Touches []Touch
+}
+
+type fileRecording struct {
+	Seed    int64        `json:"seed"`
+	Frames  []clock.Time `json:"frames"`
+	Touches []fileTouch  `json:"touches"`
+}
+
+type fileTouch struct {
+	Frame int             `json:"frame"`
+	Type  event.TouchType `json:"type"`
+	X     geom.Pt         `json:"x"`
+	Y     geom.Pt         `json:"y"`
+}
+
+// Decode reads a Recording.
+func Decode(r io.Reader) (*Recording, error) {
+	var f fileRecording
+	if err := json.NewDecoder(r).Decode(&f); err != nil {
+		return nil, fmt.Errorf("replay: %v", err)
+	}
+	rec := &Recording{
+		Seed:   f.Seed,
+		Frames: f.Frames,
+	}
+	for i, t := range f.Touches {
+		if t.Frame < 0 || t.Frame >= len(f.Frames) {
+			return nil, fmt.Errorf("replay: touch %d in frame %d, want a frame in [0, %d)", i, t.Frame, len(f.Frames))
+		}
+		if i > 0 && t.Frame < f.Touches[i-1].Frame {
+			return nil, fmt.Errorf("replay: touch %d in frame %d is before touch %d in frame %d", i, t.Frame, i-1, f.Touches[i-1].Frame)
+		}
+		rec.Touches = append(rec.Touches, Touch{
+			Frame: t.Frame,
+			Touch: event.Touch{
+				Type: t.Type,
+				Loc:  geom.Point{t.X, t.Y},
+			},
+		})
+	}
+	return rec, nil
+}
+
+// Encode writes r.
+func (r *Recording) Encode(w io.Writer) error {
+	f := fileRecording{
+		Seed:   r.Seed,
+		Frames: r.Frames,
+	}
+	for _, t := range r.Touches {
+		f.Touches = append(f.Touches, fileTouch{
+			Frame: t.Frame,
+			Type:  t.Touch.Type,
+			X:     t.Touch.Loc.X,
+			Y:     t.Touch.Loc.Y,
+		})
+	}
+	b, err := json.Marshal(&f)
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+	_, err = w.Write(b)
+	return err
+}
+
+// Recorder is a tick.Source that records the frame times of Source,
+// and the touches passed to Touch.
+type Recorder struct {
+	Source    tick.Source
+	Recording Recording
+}
+
+func (r *Recorder) Frame() clock.Time {
+	t := r.Source.Frame()
+	r.Recording.Frames = append(r.Recording.Frames, t)
+	return t
+}
+
+func (r *Recorder) Now() clock.Time { return r.Source.Now() }
+
+// Touch records that e was handled in the latest frame.
+func (r *Recorder) Touch(e event.Touch) {
+	r.Recording.Touches = append(r.Recording.Touches, Touch{
+		Frame: len(r.Recording.Frames) - 1,
+		Touch: e,
+	})
+}
+
+// Player is a tick.Source that replays the frame times of a Recording.
+// After the last recorded frame, time stops.
+type Player struct {
+	rec   *Recording
+	frame int // next frame
+	touch int // next touch
+	now   clock.Time
+}
+
+// NewPlayer returns a Player for rec.
+func NewPlayer(rec *Recording) *Player {
+	return &Player{rec: rec}
+}
+
+func (p *Player) Frame() clock.Time {
+	if p.frame < len(p.rec.Frames) {
+		p.now = p.rec.Frames[p.frame]
+		p.frame++
+	}
+	return p.now
+}
+
+func (p *Player) Now() clock.Time { return p.now }
+
+// Touches returns the recorded touches handled by the frames replayed
+// so far that have not already been returned.
+func (p *Player) Touches() []event.Touch {
+	var touches []event.Touch
+	for ; p.touch < len(p.rec.Touches) && p.rec.Touches[p.touch].Frame < p.frame; p.touch++ {
+		touches = append(touches, p.rec.Touches[p.touch].Touch)
+	}
+	return touches
+}
+
+// Done reports whether every frame has been replayed.
+func (p *Player) Done() bool {
+	return p.frame >= len(p.rec.Frames)
+}
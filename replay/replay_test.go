@@ -0,0 +1,85 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package replay
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/mobile/event"
+	"golang.org/x/mobile/geom"
+	"golang.org/x/mobile/sprite/clock"
+
+	"github.com/crawshaw/balloon/tick"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	rec := &Recording{
+		Seed:   1416531337,
+		Frames: []clock.Time{0, 1, 3, 3, 4},
+		Touches: []Touch{
+			{Frame: 2, Touch: event.Touch{Type: event.TouchStart, Loc: geom.Point{52.5, 140}}},
+			{Frame: 3, Touch: event.Touch{Type: event.TouchStart, Loc: geom.Point{10, 20}}},
+		},
+	}
+	var buf bytes.Buffer
+	if err := rec.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, rec) {
+		t.Errorf("got %+v, want %+v", got, rec)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	for _, s := range []string{
+		`{"frames": [0, 1], "touches": [{"frame": 2}]}`,
+		`{"frames": [0, 1], "touches": [{"frame": -1}]}`,
+		`{"frames": [0, 1], "touches": [{"frame": 1}, {"frame": 0}]}`,
+		`{"frames": [0, 1]`,
+	} {
+		if _, err := Decode(strings.NewReader(s)); err == nil {
+			t.Errorf("Decode(%s) succeeded, want error", s)
+		}
+	}
+}
+
+// TestSameTime replays touches handled in consecutive frames with the
+// same time, as a real clock gives above 60 frames per second.
+func TestSameTime(t *testing.T) {
+	src := new(tick.Manual)
+	r := &Recorder{Source: src}
+	a := event.Touch{Type: event.TouchStart, Loc: geom.Point{1, 1}}
+	b := event.Touch{Type: event.TouchStart, Loc: geom.Point{2, 2}}
+	r.Frame()
+	src.Advance(5)
+	r.Frame()
+	r.Touch(a)
+	r.Frame()
+	r.Touch(b)
+
+	p := NewPlayer(&r.Recording)
+	want := [][]event.Touch{nil, {a}, {b}}
+	for i, w := range want {
+		if got := p.Frame(); got != r.Recording.Frames[i] {
+			t.Errorf("frame %d: time %d, want %d", i, got, r.Recording.Frames[i])
+		}
+		if got := p.Touches(); !reflect.DeepEqual(got, w) {
+			t.Errorf("frame %d: touches %v, want %v", i, got, w)
+		}
+	}
+	if !p.Done() {
+		t.Error("not done after the last frame")
+	}
+	if got := p.Frame(); got != 5 {
+		t.Errorf("after the last frame, time %d, want 5", got)
+	}
+}
@@ -0,0 +1,288 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package scene manages a stack of game screens and the transitions
+// between them.
+//
+// The top Scene of a Manager's stack receives updates and touches.
+// Render the Manager's Node to draw it.
+package scene
+
+import (
+	"image"
+	"image/color"
+
+	"golang.org/x/mobile/event"
+	"golang.org/x/mobile/geom"
+	"golang.org/x/mobile/sprite"
+	"golang.org/x/mobile/sprite/clock"
+
+	"github.com/crawshaw/balloon/animation"
+	"github.com/crawshaw/balloon/tint"
+)
+
+// Scene is a screen of the game, such as a menu or a level.
+//
+// The hooks are optional. Enter is called when the scene becomes the top
+// of the stack and Exit when it stops being the top, both at the start
+// of any transition. Update is called each frame and Touch for each
+// touch while the scene is the top. Touches are dropped during a
+// transition.
+type Scene struct {
+	Node    *sprite.Node
+	Overlay bool // the scene below is drawn beneath this one
+
+	Enter  func(t clock.Time)
+	Exit   func(t clock.Time)
+	Update func(t clock.Time)
+	Touch  func(e event.Touch, t clock.Time)
+
+	holder *sprite.Node
+	pos    *animation.Arrangement // arrangement of holder
+}
+
+// Effect is how a Transition animates from one scene to the next.
+type Effect int
+
+const (
+	Cut        Effect = iota // switch immediately
+	Fade                     // fade out to a color, then fade in
+	SlideLeft                // the new scene enters from the right
+	SlideRight               // the new scene enters from the left
+)
+
+// Transition is an animated change of scene.
+type Transition struct {
+	Effect   Effect
+	Duration clock.Time
+	Color    color.Color // color faded through, white if nil
+}
+
+// Manager is a stack of scenes.
+type Manager struct {
+	Node *sprite.Node
+
+	eng      sprite.Engine
+	stack    []*Scene
+	tr       Transition
+	t0       clock.Time
+	from     []*Scene // scenes drawn when the transition started
+	switched bool     // a Fade is showing the new scenes
+	fade     *animation.Arrangement
+	fadeNode *sprite.Node
+}
+
+// NewManager returns an empty Manager drawing with e.
+func NewManager(e sprite.Engine) (*Manager, error) {
+	white := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	white.Pix[0], white.Pix[1], white.Pix[2], white.Pix[3] = 0xff, 0xff, 0xff, 0xff
+	tex, err := tint.LoadTexture(e, white)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Manager{
+		Node: new(sprite.Node),
+		eng:  e,
+		fade: &animation.Arrangement{
+			SubTex: sprite.SubTex{T: tex, R: white.Bounds()},
+			Hidden: true,
+		},
+	}
+	m.fadeNode = &sprite.Node{Arranger: m.fade}
+	e.Register(m.Node)
+	e.Register(m.fadeNode)
+	m.Node.AppendChild(m.fadeNode)
+	return m, nil
+}
+
+// Top returns the top scene, or nil if the stack is empty.
+func (m *Manager) Top() *Scene {
+	return top(m.stack)
+}
+
+func top(stack []*Scene) *Scene {
+	if len(stack) == 0 {
+		return nil
+	}
+	return stack[len(stack)-1]
+}
+
+// Push makes s the top scene.
+func (m *Manager) Push(t clock.Time, s *Scene, tr Transition) {
+	stack := append(m.stack[:len(m.stack):len(m.stack)], s)
+	m.change(t, stack, tr)
+}
+
+// Pop removes the top scene.
+func (m *Manager) Pop(t clock.Time, tr Transition) {
+	if len(m.stack) == 0 {
+		return
+	}
+	m.change(t, m.stack[:len(m.stack)-1], tr)
+}
+
+// Replace replaces the top scene with s.
+func (m *Manager) Replace(t clock.Time, s *Scene, tr Transition) {
+	if len(m.stack) == 0 {
+		m.Push(t, s, tr)
+		return
+	}
+	stack := append(m.stack[:len(m.stack)-1:len(m.stack)-1], s)
+	m.change(t, stack, tr)
+}
+
+// Update advances any transition and updates the top scene.
+// Call it once per frame, before rendering Node.
+func (m *Manager) Update(t clock.Time) {
+	if m.tr.Effect != Cut {
+		switch {
+		case t >= m.t0+m.tr.Duration:
+			m.finish()
+		case m.tr.Effect == Fade && !m.switched && t >= m.t0+m.tr.Duration/2:
+			m.switched = true
+			m.show(visible(m.stack))
+		}
+	}
+	if s := m.Top(); s != nil && s.Update != nil {
+		s.Update(t)
+	}
+}
+
+// Touch passes e to the top scene.
+func (m *Manager) Touch(e event.Touch, t clock.Time) {
+	if m.tr.Effect != Cut {
+		return
+	}
+	if s := m.Top(); s != nil && s.Touch != nil {
+		s.Touch(e, t)
+	}
+}
+
+func (m *Manager) change(t clock.Time, stack []*Scene, tr Transition) {
+	m.finish()
+	from, to := m.Top(), top(stack)
+	m.from = visible(m.stack)
+	m.stack = stack
+	if from != to {
+		if from != nil && from.Exit != nil {
+			from.Exit(t)
+		}
+		if to != nil && to.Enter != nil {
+			to.Enter(t)
+		}
+	}
+
+	if tr.Duration <= 0 {
+		tr.Effect = Cut
+	}
+	m.tr, m.t0 = tr, t
+	next := visible(m.stack)
+	switch tr.Effect {
+	case Cut:
+		m.show(next)
+	case Fade:
+		m.switched = false
+		m.show(m.from)
+		m.fade.Hidden = false
+		m.fade.Tint = tr.Color
+		m.fade.Fade = 1
+		m.fade.Size = &geom.Point{geom.Width, geom.Height}
+		m.fade.T0, m.fade.T1 = t, t+tr.Duration
+		m.fade.Transform = animation.Transform{
+			Transformer: animation.Sequence{animation.Fade(-1), animation.Fade(1)},
+		}
+	case SlideLeft, SlideRight:
+		m.show(union(m.from, next))
+		dx := geom.Width
+		if tr.Effect == SlideRight {
+			dx = -dx
+		}
+		if from != nil && !contains(next, from) {
+			from.slide(0, -dx, t, t+tr.Duration)
+		}
+		if to != nil && !contains(m.from, to) {
+			to.slide(dx, -dx, t, t+tr.Duration)
+		}
+	}
+}
+
+// finish ends any transition in progress.
+func (m *Manager) finish() {
+	if m.tr.Effect == Cut {
+		return
+	}
+	for _, s := range m.from {
+		s.slide(0, 0, 0, 0)
+	}
+	for _, s := range m.stack {
+		s.slide(0, 0, 0, 0)
+	}
+	m.fade.Hidden = true
+	m.fade.Transform = animation.Transform{}
+	m.tr = Transition{}
+	m.from = nil
+	m.show(visible(m.stack))
+}
+
+// show makes scenes, bottom first, the children of m.Node.
+func (m *Manager) show(scenes []*Scene) {
+	for c := m.Node.FirstChild; c != nil; c = m.Node.FirstChild {
+		m.Node.RemoveChild(c)
+	}
+	for _, s := range scenes {
+		if s.holder == nil {
+			s.pos = new(animation.Arrangement)
+			s.holder = &sprite.Node{Arranger: s.pos}
+			m.eng.Register(s.holder)
+			s.holder.AppendChild(s.Node)
+		}
+		m.Node.AppendChild(s.holder)
+	}
+	m.Node.AppendChild(m.fadeNode)
+}
+
+// slide places s at x and moves it by dx between t0 and t1.
+func (s *Scene) slide(x, dx geom.Pt, t0, t1 clock.Time) {
+	if s.pos == nil {
+		return
+	}
+	s.pos.Offset.X = x
+	s.pos.T0, s.pos.T1 = t0, t1
+	s.pos.Transform = animation.Transform{}
+	if dx != 0 {
+		s.pos.Transform.Transformer = animation.Move{X: dx}
+	}
+}
+
+// visible returns the scenes of stack that are drawn, bottom first.
+func visible(stack []*Scene) []*Scene {
+	i := len(stack) - 1
+	for i > 0 && stack[i].Overlay {
+		i--
+	}
+	if i < 0 {
+		return nil
+	}
+	return stack[i:]
+}
+
+func union(a, b []*Scene) []*Scene {
+	u := append([]*Scene(nil), a...)
+	for _, s := range b {
+		if !contains(u, s) {
+			u = append(u, s)
+		}
+	}
+	return u
+}
+
+func contains(scenes []*Scene, s *Scene) bool {
+	for _, x := range scenes {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
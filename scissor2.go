@@ -1,8 +1,10 @@
 package main
 
 import (
+	"fmt"
 	"math"
 
+	"golang.org/x/mobile/f32"
 	"golang.org/x/mobile/geom"
 	"golang.org/x/mobile/sprite"
 	"golang.org/x/mobile/sprite/clock"
@@ -16,11 +18,14 @@ const maxExtend = geom.Pt(72) // TODO
 
 type scissorArm2 struct {
 	a        *animation.Animation
+	ready    bool // arm is closed and loaded, ready to fire
+	firing   bool // arm is extending or retracting
 	extend   geom.Pt
 	numFolds int
 
 	node        *sprite.Node
 	arrangement animation.Arrangement
+	balloon     *sprite.Node // the balloon held at the end of the arm
 }
 
 func (s *scissorArm2) balloonTravel() (minX, maxX geom.Pt) {
@@ -35,6 +40,11 @@ func (s *scissorArm2) Arrange(e sprite.Engine, n *sprite.Node, t clock.Time) {
 	s.arrangement.Arrange(e, n, t)
 }
 
+// AffineAt implements hit.Affiner.
+func (s *scissorArm2) AffineAt(t clock.Time) f32.Affine {
+	return s.arrangement.AffineAt(t)
+}
+
 func (s *scissorArm2) moveArm(ar *animation.Arrangement, tween float32) {
 	ar.Offset.X += 18 * geom.Pt(tween) * (s.extend / maxExtend)
 }
@@ -60,6 +70,13 @@ func newScissorArm2(eng sprite.Engine) *scissorArm2 {
 	s.node.Arranger = s
 	eng.Register(s.node)
 
+	// Node for sliding the entire assembly on and off screen.
+	slide := &sprite.Node{
+		Arranger: &animation.Arrangement{Name: "slide"},
+	}
+	eng.Register(slide)
+	s.node.AppendChild(slide)
+
 	base := new(sprite.Node)
 	eng.Register(base)
 	base.Arranger = &animation.Arrangement{
@@ -68,35 +85,39 @@ func newScissorArm2(eng sprite.Engine) *scissorArm2 {
 		Rotation: math.Pi,
 		SubTex:   sheet.pad, // TODO: get a better texture
 	}
-	s.node.AppendChild(base)
+	slide.AppendChild(base)
 
 	moveArm := animation.TransformerFunc(s.moveArm)
 	moveArmBack := animation.TransformerFunc(s.moveArmBack)
 	rotateArm := animation.TransformerFunc(s.rotateArm)
 	rotateArmBack := animation.TransformerFunc(s.rotateArmBack)
 
-	expanding := make(map[*sprite.Node]animation.Transform)
-	contracting := make(map[*sprite.Node]animation.Transform)
+	expanding := make(map[string]animation.Transform)
+	contracting := make(map[string]animation.Transform)
 
 	// TODO: have i inverted top and bottom naming here?
 
-	parent := s.node
+	parent, path := slide, "slide"
 	for i := 0; i < s.numFolds; i++ {
 		offX := geom.Pt(10)
 		if i == 0 {
 			offX = 5
 		}
+		name := fmt.Sprintf("bottom%d", i)
 		b := new(sprite.Node)
-		b.Arranger = &animation.Arrangement{Offset: geom.Point{X: offX}}
+		b.Arranger = &animation.Arrangement{
+			Name:   name,
+			Offset: geom.Point{X: offX},
+		}
 		eng.Register(b)
 		parent.AppendChild(b)
-		parent = b
+		parent, path = b, path+"/"+name
 
-		expanding[b] = animation.Transform{
+		expanding[path] = animation.Transform{
 			Tween:       clock.EaseIn,
 			Transformer: moveArm,
 		}
-		contracting[b] = animation.Transform{
+		contracting[path] = animation.Transform{
 			Tween:       clock.EaseIn,
 			Transformer: moveArmBack,
 		}
@@ -104,6 +125,7 @@ func newScissorArm2(eng sprite.Engine) *scissorArm2 {
 		arm := new(sprite.Node)
 		eng.Register(arm)
 		a := &animation.Arrangement{
+			Name:     "arm",
 			Pivot:    geom.Point{X: 18, Y: 4},
 			Size:     &geom.Point{X: 36, Y: 9},
 			Rotation: 1.2,
@@ -112,33 +134,37 @@ func newScissorArm2(eng sprite.Engine) *scissorArm2 {
 		arm.Arranger = a
 		b.AppendChild(arm)
 
-		expanding[arm] = animation.Transform{
+		expanding[path+"/arm"] = animation.Transform{
 			Tween:       clock.EaseIn,
 			Transformer: rotateArmBack,
 		}
-		contracting[arm] = animation.Transform{
+		contracting[path+"/arm"] = animation.Transform{
 			Tween:       clock.EaseIn,
 			Transformer: rotateArm,
 		}
 	}
 
-	parent = s.node
+	parent, path = slide, "slide"
 	for i := 0; i < s.numFolds; i++ {
 		offX := geom.Pt(10)
 		if i == 0 {
 			offX = 5
 		}
+		name := fmt.Sprintf("top%d", i)
 		t := new(sprite.Node)
-		t.Arranger = &animation.Arrangement{Offset: geom.Point{X: offX}}
+		t.Arranger = &animation.Arrangement{
+			Name:   name,
+			Offset: geom.Point{X: offX},
+		}
 		eng.Register(t)
 		parent.AppendChild(t)
-		parent = t
+		parent, path = t, path+"/"+name
 
-		expanding[t] = animation.Transform{
+		expanding[path] = animation.Transform{
 			Tween:       clock.EaseIn,
 			Transformer: moveArm,
 		}
-		contracting[t] = animation.Transform{
+		contracting[path] = animation.Transform{
 			Tween:       clock.EaseIn,
 			Transformer: moveArmBack,
 		}
@@ -146,6 +172,7 @@ func newScissorArm2(eng sprite.Engine) *scissorArm2 {
 		arm := new(sprite.Node)
 		eng.Register(arm)
 		a := &animation.Arrangement{
+			Name:     "arm",
 			Pivot:    geom.Point{X: 18, Y: 4},
 			Size:     &geom.Point{X: 36, Y: 9},
 			Rotation: -1.2,
@@ -154,11 +181,11 @@ func newScissorArm2(eng sprite.Engine) *scissorArm2 {
 		arm.Arranger = a
 		t.AppendChild(arm)
 
-		expanding[arm] = animation.Transform{
+		expanding[path+"/arm"] = animation.Transform{
 			Tween:       clock.EaseIn,
 			Transformer: rotateArm,
 		}
-		contracting[arm] = animation.Transform{
+		contracting[path+"/arm"] = animation.Transform{
 			Tween:       clock.EaseIn,
 			Transformer: rotateArmBack,
 		}
@@ -179,6 +206,7 @@ func newScissorArm2(eng sprite.Engine) *scissorArm2 {
 	// Balloon.
 	p = new(sprite.Node)
 	eng.Register(p)
+	s.balloon = p
 	p.Arranger = &animation.Arrangement{
 		Offset: geom.Point{X: 14, Y: -72 + 36/2},
 		Pivot:  geom.Point{X: 6, Y: 18},
@@ -187,51 +215,33 @@ func newScissorArm2(eng sprite.Engine) *scissorArm2 {
 	}
 	parent.AppendChild(p)
 
-	// New top-level node for moving around entire assembly.
-	p = &sprite.Node{
-		Arranger: &animation.Arrangement{},
-	}
-	eng.Register(p)
-	p.AppendChild(s.node)
-	s.node = p
-
 	size := geom.Pt(s.numFolds*10 + 12)
 
 	s.a = &animation.Animation{
-		Current: "init",
+		Current:   "init",
+		Crossfade: 4,
 		States: map[string]animation.State{
 			"init": animation.State{},
-			"offscreen": animation.State{
-				Next: "onscreen",
-				Transforms: map[*sprite.Node]animation.Transform{
-					s.node: animation.Transform{
-						Tween:       clock.EaseInOut,
-						Transformer: animation.Move{X: -size},
-					},
-				},
-			},
-			"onscreen": animation.State{
-				Duration: 60,
-				Next:     "closed",
-				Transforms: map[*sprite.Node]animation.Transform{
-					s.node: animation.Transform{
-						Tween:       clock.EaseInOut,
-						Transformer: animation.Move{X: size},
-					},
-				},
-			},
 			"closed": animation.State{
 				Duration: 5,
 				Next:     "loading_balloon",
+				OnEnter:  func(*animation.Animation, clock.Time) { s.firing = false },
 			},
 			"loading_balloon": animation.State{
 				Next: "ready",
 			},
-			"ready": animation.State{},
+			"ready": animation.State{
+				Edges: []animation.Edge{
+					{Next: "expanding", Trigger: "fire"},
+				},
+				OnEnter: func(*animation.Animation, clock.Time) { s.ready = true },
+				OnExit:  func(*animation.Animation, clock.Time) { s.ready = false },
+			},
 			"expanding": animation.State{
 				Duration:   expandTime,
 				Next:       "open",
 				Transforms: expanding,
+				OnEnter:    func(*animation.Animation, clock.Time) { s.firing = true },
 			},
 			"open": animation.State{
 				Duration: 5,
@@ -243,8 +253,39 @@ func newScissorArm2(eng sprite.Engine) *scissorArm2 {
 				Transforms: contracting,
 			},
 		},
+		Layers: []*animation.Layer{{
+			Name:    "slide",
+			Current: "init",
+			States: map[string]animation.State{
+				"init": animation.State{},
+				"offscreen": animation.State{
+					Next: "onscreen",
+					Transforms: map[string]animation.Transform{
+						"slide": animation.Transform{
+							Tween:       clock.EaseInOut,
+							Transformer: animation.Move{X: -size},
+						},
+					},
+				},
+				"onscreen": animation.State{
+					Duration: 60,
+					Next:     "shown",
+					Transforms: map[string]animation.Transform{
+						"slide": animation.Transform{
+							Tween:       clock.EaseInOut,
+							Transformer: animation.Move{X: size},
+						},
+					},
+				},
+				"shown": animation.State{
+					OnEnter: func(a *animation.Animation, t clock.Time) {
+						a.Transition(t, "closed")
+					},
+				},
+			},
+		}},
 	}
-	s.a.Transition(0, "offscreen")
+	s.a.TransitionLayer(0, "slide", "offscreen")
 
 	return s
 }
@@ -5,14 +5,13 @@
 package main
 
 import (
-	"bytes"
 	"fmt"
-	"image"
 	"image/color"
-	"image/png"
 	"io/ioutil"
 	"log"
+	"math/rand"
 	"runtime"
+	"sync"
 	"time"
 
 	"code.google.com/p/freetype-go/freetype"
@@ -24,7 +23,19 @@ import (
 	"golang.org/x/mobile/sprite/clock"
 
 	"github.com/crawshaw/balloon/animation"
+	"github.com/crawshaw/balloon/atlas"
+	"github.com/crawshaw/balloon/hit"
+	"github.com/crawshaw/balloon/replay"
+	"github.com/crawshaw/balloon/scene"
 	"github.com/crawshaw/balloon/text"
+	"github.com/crawshaw/balloon/tick"
+)
+
+// Text colors.
+var (
+	titleColor    = color.NRGBA{0x00, 0x7d, 0x9c, 0xff} // gopher blue
+	gameOverColor = color.NRGBA{0xce, 0x30, 0x62, 0xff}
+	scoreColor    = color.NRGBA{0x40, 0x40, 0x40, 0xff}
 )
 
 var sheet struct {
@@ -37,20 +48,56 @@ var sheet struct {
 }
 
 var (
-	start time.Time
-	eng   sprite.Engine
-	font  *truetype.Font
+	clk  tick.Source // game time, real time if nil at timerInit
+	eng  sprite.Engine
+	font *truetype.Font
 )
 
 var (
-	scene     *sprite.Node
-	gameScene *sprite.Node
-	menuScene *sprite.Node
-	overScene *sprite.Node
+	seed     int64            // seed of game.rand
+	recorder *replay.Recorder // records the session, unless replaying
+	player   *replay.Player   // replays a session
 )
 
+// touches holds the touches received since the last frame.
+var touches struct {
+	sync.Mutex
+	pending []event.Touch
+}
+
+var (
+	scenes    *scene.Manager
+	gameScene *scene.Scene
+	menuScene *scene.Scene
+	overScene *scene.Scene
+)
+
+// buttons maps the nodes that respond to touches to their actions.
+var buttons = make(map[*sprite.Node]func(e event.Touch, t clock.Time))
+
+// addButton adds an invisible button covering r to parent.
+func addButton(parent *sprite.Node, r geom.Rectangle, action func(e event.Touch, t clock.Time)) {
+	size := geom.Point{r.Max.X - r.Min.X, r.Max.Y - r.Min.Y}
+	n := &sprite.Node{
+		Arranger: &animation.Arrangement{
+			Offset: r.Min,
+			Size:   &size,
+		},
+	}
+	eng.Register(n)
+	parent.AppendChild(n)
+	buttons[n] = action
+}
+
+// screen is the whole screen.
+func screen() geom.Rectangle {
+	return geom.Rectangle{Max: geom.Point{geom.Width, geom.Height}}
+}
+
 func timerInit() {
-	start = time.Now()
+	if err := initReplay(); err != nil {
+		log.Fatal(err)
+	}
 	if err := loadSheet(); err != nil {
 		log.Fatal(err)
 	}
@@ -61,19 +108,37 @@ func timerInit() {
 		panic(err)
 	}
 
+	scenes, err = scene.NewManager(eng)
+	if err != nil {
+		log.Fatal(err)
+	}
 	menuSceneInit()
 	gameSceneInit()
 	overSceneInit()
-	scene = menuScene
+	scenes.Push(0, menuScene, scene.Transition{})
+}
+
+// newScene returns a Scene whose touches press its buttons.
+func newScene() *scene.Scene {
+	n := new(sprite.Node)
+	eng.Register(n)
+	return &scene.Scene{
+		Node:  n,
+		Touch: pressButton,
+	}
 }
 
 func menuSceneInit() {
-	menuScene = new(sprite.Node)
-	eng.Register(menuScene)
+	menuScene = newScene()
 
 	addGopher := func(offsetX, size geom.Pt, subTex sprite.SubTex, duration int) {
+		gopherAnim := new(sprite.Node)
+		eng.Register(gopherAnim)
+		menuScene.Node.AppendChild(gopherAnim)
+
 		gopher := &sprite.Node{
 			Arranger: &animation.Arrangement{
+				Name:   "gopher",
 				Offset: geom.Point{X: offsetX, Y: -size},
 				Size:   &geom.Point{size, size},
 				Pivot:  geom.Point{size / 2, size / 2},
@@ -81,11 +146,9 @@ func menuSceneInit() {
 			},
 		}
 		eng.Register(gopher)
-		menuScene.AppendChild(gopher)
+		gopherAnim.AppendChild(gopher)
 
-		gopherAnim := new(sprite.Node)
-		eng.Register(gopherAnim)
-		menuScene.AppendChild(gopherAnim)
+		fall := geom.Height + size*2
 		gopherAnim.Arranger = &animation.Animation{
 			Current: "init",
 			States: map[string]animation.State{
@@ -96,18 +159,34 @@ func menuSceneInit() {
 				"falling": animation.State{
 					Duration: duration,
 					Next:     "reset",
-					Transforms: map[*sprite.Node]animation.Transform{
-						gopher: animation.Transform{
-							Transformer: animation.Move{Y: geom.Height + size*2},
+					Transforms: map[string]animation.Transform{
+						"gopher": animation.Transform{
+							Transformer: animation.Move{Y: fall},
+						},
+					},
+				},
+				"swaying": animation.State{
+					Duration: duration,
+					Next:     "reset",
+					Transforms: map[string]animation.Transform{
+						"gopher": animation.Transform{
+							Transformer: animation.Keyframes{
+								{At: 0.25, Offset: geom.Point{X: size / 2, Y: fall / 4}, Rotation: -0.3, Tween: clock.EaseOut},
+								{At: 0.75, Offset: geom.Point{X: -size / 2, Y: fall * 3 / 4}, Rotation: 0.3, Tween: clock.EaseInOut},
+								{At: 1, Offset: geom.Point{Y: fall}, Tween: clock.EaseIn},
+							},
 						},
 					},
 				},
 				"reset": animation.State{
 					Duration: 0,
-					Next:     "falling",
-					Transforms: map[*sprite.Node]animation.Transform{
-						gopher: animation.Transform{
-							Transformer: animation.Move{Y: -geom.Height - size*2},
+					Edges: []animation.Edge{
+						{Next: "falling", Weight: 2},
+						{Next: "swaying", Weight: 1},
+					},
+					Transforms: map[string]animation.Transform{
+						"gopher": animation.Transform{
+							Transformer: animation.Move{Y: -fall},
 						},
 					},
 				},
@@ -119,64 +198,93 @@ func menuSceneInit() {
 	addGopher(48, 18, sheet.gopherRun, 100)
 	addGopher(96, 36, sheet.gopherSwim, 160)
 
-	addText(menuScene, "Gopher Fall!", 20, geom.Point{24, 24})
-	addText(menuScene, "Tap to start", 14, geom.Point{48, 48})
+	y := addText(menuScene.Node, "Gopher Fall!", 20, titleColor, textMargin)
+	addText(menuScene.Node, "Tap to start", 14, color.Black, y+textMargin)
+	addButton(menuScene.Node, screen(), func(e event.Touch, t clock.Time) {
+		scenes.Replace(t, gameScene, scene.Transition{Effect: scene.SlideLeft, Duration: 20})
+	})
 }
 
 func overSceneInit() {
-	overScene = new(sprite.Node)
-	eng.Register(overScene)
+	overScene = newScene()
+	overScene.Enter = func(clock.Time) { saveReplay() }
 
-	addText(overScene, "GAME OVER", 20, geom.Point{28, 28})
-	addText(overScene, "Tap to play again", 14, geom.Point{32, 48})
+	y := addText(overScene.Node, "GAME OVER", 20, gameOverColor, textMargin)
+	addText(overScene.Node, "Tap to play again", 14, color.Black, y+textMargin)
+	addButton(overScene.Node, screen(), func(e event.Touch, t clock.Time) {
+		scenes.Replace(t, menuScene, scene.Transition{Effect: scene.SlideRight, Duration: 20})
+	})
 }
 
-func addText(parent *sprite.Node, str string, size geom.Pt, pos geom.Point) {
+// textMargin is the space between centered text and the screen edges.
+const textMargin = 8
+
+// addText adds str to parent, centered on the screen with its top at
+// y, and returns the y of its bottom. Long text is wrapped.
+func addText(parent *sprite.Node, str string, size geom.Pt, c color.Color, y geom.Pt) geom.Pt {
+	m, err := text.Measure(font, size, str)
+	if err != nil {
+		log.Fatal(err)
+	}
+	s := &text.String{
+		Size:  size,
+		Color: c,
+		Font:  font,
+		Text:  str,
+		Width: geom.Width - 2*textMargin,
+		Align: text.Center,
+	}
+
 	p := &sprite.Node{
 		Arranger: &animation.Arrangement{
-			Offset: pos,
+			Offset: geom.Point{X: textMargin, Y: y + m.Ascent},
 		},
 	}
 	eng.Register(p)
 	parent.AppendChild(p)
-	pText := &sprite.Node{
-		Arranger: &text.String{
-			Size:  size,
-			Color: color.Black,
-			Font:  font,
-			Text:  str,
-		},
-	}
+	pText := &sprite.Node{Arranger: s}
 	eng.Register(pText)
 	p.AppendChild(pText)
+
+	return y + geom.Pt(len(s.Layout()))*(m.Ascent+m.Descent)
 }
 
 func gameSceneInit() {
-	gameScene = new(sprite.Node)
-	eng.Register(gameScene)
+	game.rand = rand.New(rand.NewSource(seed))
+
+	gameScene = newScene()
+	gameScene.Enter = func(clock.Time) { startGame() }
+	gameScene.Update = updateGame
 
 	game.scissor = newScissorArm2(eng)
 	game.scissor.arrangement.Offset.Y = 2 * 72
-	gameScene.AppendChild(game.scissor.node)
+	gameScene.Node.AppendChild(game.scissor.node)
 
+	const scoreSize = 12
+	m, err := text.Measure(font, scoreSize, "")
+	if err != nil {
+		log.Fatal(err)
+	}
 	n1 := new(sprite.Node)
 	eng.Register(n1)
 	n1.Arranger = &animation.Arrangement{
-		Offset: geom.Point{X: 0, Y: geom.Height - 12 - 2},
+		Offset: geom.Point{X: 0, Y: geom.Height - m.Descent - 2},
 	}
-	gameScene.AppendChild(n1)
+	gameScene.Node.AppendChild(n1)
 
 	t := new(sprite.Node)
 	eng.Register(t)
 	n1.AppendChild(t)
 	game.scoreText = &text.String{
-		Size:  12,
-		Color: color.Black,
+		Size:  scoreSize,
+		Color: scoreColor,
 		Font:  font,
 	}
 	t.Arranger = game.scoreText
 
-	updateGame(0)
+	addButton(gameScene.Node, screen(), func(e event.Touch, t clock.Time) {
+		game.nextTouch = &e
+	})
 
 	//Fprint(os.Stdout, gameScene, NotNilFilter)
 }
@@ -203,52 +311,117 @@ func loadFont() (*truetype.Font, error) {
 }
 
 func loadSheet() error {
-	f, err := app.Open("balloon_sheet.png")
+	a, err := atlas.Open(eng, "balloon_sheet.png")
 	if err != nil {
 		return err
 	}
-	mb, err := ioutil.ReadAll(f)
-	if err != nil {
-		return err
+	sheet.sheet = a.Texture
+
+	subTex := func(name string) sprite.SubTex {
+		x, e := a.SubTex(name)
+		if err == nil {
+			err = e
+		}
+		return x
 	}
-	m, err := png.Decode(bytes.NewReader(mb))
-	if err != nil {
-		return err
+	sheet.arm = subTex("arm")
+	sheet.balloon = subTex("balloon")
+	sheet.pad = subTex("pad")
+	sheet.gopherSwim = subTex("gopher_swim")
+	sheet.gopherRun = subTex("gopher_run")
+
+	return err
+}
+
+func touch(e event.Touch) {
+	if e.Type != event.TouchStart || player != nil {
+		return
 	}
-	t, err := eng.LoadTexture(m)
-	if err != nil {
-		return err
+	touches.Lock()
+	touches.pending = append(touches.pending, e)
+	touches.Unlock()
+}
+
+// frame advances the game to its next frame and returns the frame time.
+//
+// Touches are handled at the start of the frame after they arrive, so
+// that a recorded session replays exactly.
+func frame() clock.Time {
+	t := clk.Frame()
+	var pending []event.Touch
+	if player != nil {
+		pending = player.Touches()
+	} else {
+		touches.Lock()
+		pending, touches.pending = touches.pending, nil
+		touches.Unlock()
 	}
-	sheet.sheet = t
+	for _, e := range pending {
+		if recorder != nil {
+			recorder.Touch(e)
+		}
+		scenes.Touch(e, t)
+	}
+	scenes.Update(t)
+	return t
+}
 
-	sheet.arm = sprite.SubTex{t, image.Rect(0, 0, 194, 42)}
-	sheet.balloon = sprite.SubTex{t, image.Rect(0, 42, 148, 634)}
-	sheet.pad = sprite.SubTex{t, image.Rect(194, 0, 294, 286)}
-	sheet.gopherSwim = sprite.SubTex{t, image.Rect(188, 288, 294, 380)}
-	sheet.gopherRun = sprite.SubTex{t, image.Rect(194, 380, 240, 440)}
+// initReplay replays the session in the asset replay.json if there is
+// one, and otherwise starts recording the session.
+func initReplay() error {
+	if f, err := app.Open("replay.json"); err == nil {
+		defer f.Close()
+		rec, err := replay.Decode(f)
+		if err != nil {
+			return err
+		}
+		seed = rec.Seed
+		player = replay.NewPlayer(rec)
+		clk = player
+		return nil
+	}
 
+	if clk == nil {
+		clk = tick.NewReal()
+	}
+	seed = time.Now().UnixNano()
+	recorder = &replay.Recorder{
+		Source:    clk,
+		Recording: replay.Recording{Seed: seed},
+	}
+	clk = recorder
 	return nil
 }
 
-func touch(e event.Touch) {
-	if e.Type != event.TouchStart {
+// saveReplay writes the recorded session to a temporary file, which can
+// be attached to a bug report and replayed as the asset replay.json.
+func saveReplay() {
+	if recorder == nil {
 		return
 	}
-
-	switch scene {
-	case overScene:
-		scene = menuScene
-	case menuScene:
-		startGame()
-		scene = gameScene
-	case gameScene:
-		game.nextTouch = &e
-	default:
-		log.Printf("touch in unknown state %v", e)
+	f, err := ioutil.TempFile("", "gopherfall-replay")
+	if err != nil {
+		log.Printf("saving replay: %v", err)
+		return
 	}
+	err = recorder.Recording.Encode(f)
+	if err1 := f.Close(); err == nil {
+		err = err1
+	}
+	if err != nil {
+		log.Printf("saving replay: %v", err)
+		return
+	}
+	log.Printf("replay saved to %s", f.Name())
 }
 
-func now() clock.Time {
-	d := time.Since(start)
-	return clock.Time(60 * d / time.Second)
+// pressButton runs the action of the topmost button of the top scene
+// under e.
+func pressButton(e event.Touch, t clock.Time) {
+	n := hit.Test(scenes.Top().Node, t, e.Loc, func(n *sprite.Node) bool {
+		return buttons[n] != nil
+	})
+	if n != nil {
+		buttons[n](e, t)
+	}
 }
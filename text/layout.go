@@ -0,0 +1,92 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package text
+
+import (
+	"strings"
+
+	"golang.org/x/mobile/geom"
+)
+
+// Align is the horizontal alignment of lines of text.
+type Align int
+
+const (
+	Left Align = iota
+	Center
+	Right
+)
+
+// Line is a line of laid out text.
+type Line struct {
+	Text  string
+	X, Y  geom.Pt // start of the baseline
+	Width geom.Pt // advance width
+}
+
+// Layout breaks s into lines and positions them.
+//
+// Lines are broken at newlines and, if s.Width is positive, between
+// words so that lines are no wider than s.Width. A word wider than
+// s.Width is put on a line of its own. Lines are aligned within s.Width,
+// or within the widest line if s.Width is zero. The first baseline is
+// at y=0, and each following one is s.LineSpacing times the height of
+// the font below it.
+func (s *String) Layout() []Line {
+	if s.Font == nil {
+		return nil
+	}
+	scale := floatToFix(s.Size.Px())
+	measure := func(text string) geom.Pt {
+		return geom.Pt(layoutGlyphs(s.Font, scale, text, nil) / geom.PixelsPerPt)
+	}
+
+	var lines []Line
+	for _, para := range strings.Split(s.Text, "\n") {
+		if s.Width <= 0 {
+			lines = append(lines, Line{Text: para, Width: measure(para)})
+			continue
+		}
+		line := ""
+		for i, word := range strings.Split(para, " ") {
+			next := word
+			if i > 0 {
+				next = line + " " + word
+			}
+			if i > 0 && line != "" && measure(next) > s.Width {
+				lines = append(lines, Line{Text: line, Width: measure(line)})
+				next = word
+			}
+			line = next
+		}
+		lines = append(lines, Line{Text: line, Width: measure(line)})
+	}
+
+	width := s.Width
+	if width <= 0 {
+		for _, l := range lines {
+			if l.Width > width {
+				width = l.Width
+			}
+		}
+	}
+	spacing := s.LineSpacing
+	if spacing == 0 {
+		spacing = 1
+	}
+	b := s.Font.Bounds(scale)
+	height := geom.Pt(fixToFloat(b.YMax-b.YMin) / geom.PixelsPerPt * spacing)
+	for i := range lines {
+		l := &lines[i]
+		switch s.Align {
+		case Center:
+			l.X = (width - l.Width) / 2
+		case Right:
+			l.X = width - l.Width
+		}
+		l.Y = geom.Pt(i) * height
+	}
+	return lines
+}
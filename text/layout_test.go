@@ -0,0 +1,123 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package text
+
+import (
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	"code.google.com/p/freetype-go/freetype"
+	"code.google.com/p/freetype-go/freetype/truetype"
+	"golang.org/x/mobile/geom"
+)
+
+// testFont returns the Go font, also used by the harness tests.
+func testFont(t *testing.T) *truetype.Font {
+	b, err := ioutil.ReadFile("../harness/testdata/Go-Regular.ttf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := freetype.ParseFont(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	geom.PixelsPerPt = 1
+	return f
+}
+
+func advance(t *testing.T, f *truetype.Font, size geom.Pt, text string) geom.Pt {
+	m, err := Measure(f, size, text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m.Advance
+}
+
+func TestLayoutLines(t *testing.T) {
+	f := testFont(t)
+	const size = 12
+	w := func(text string) geom.Pt { return advance(t, f, size, text) }
+	tests := []struct {
+		name  string
+		text  string
+		width geom.Pt
+		want  []string
+	}{
+		{"one line", "one two three", 0, []string{"one two three"}},
+		{"newlines", "one\ntwo three\n", 0, []string{"one", "two three", ""}},
+		{"wrap", "one two three", w("one two") + 1, []string{"one two", "three"}},
+		{"wrap each word", "one two three", w("one"), []string{"one", "two", "three"}},
+		{"wrap and newline", "one two\nthree four", w("one two"), []string{"one two", "three", "four"}},
+		{"long word", "a incomprehensibilities b", w("a b"), []string{"a", "incomprehensibilities", "b"}},
+		{"long first word", "incomprehensibilities a b", w("a b"), []string{"incomprehensibilities", "a b"}},
+	}
+	for _, tt := range tests {
+		s := &String{Text: tt.text, Size: size, Font: f, Width: tt.width}
+		var got []string
+		for _, l := range s.Layout() {
+			got = append(got, l.Text)
+			if l.Width != w(l.Text) {
+				t.Errorf("%s: line %q is %v wide, want %v", tt.name, l.Text, l.Width, w(l.Text))
+			}
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got lines %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLayoutPosition(t *testing.T) {
+	f := testFont(t)
+	const size = 12
+	m, err := Measure(f, size, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	height := m.Ascent + m.Descent
+	short, long := advance(t, f, size, "a"), advance(t, f, size, "longer line")
+
+	tests := []struct {
+		name    string
+		align   Align
+		width   geom.Pt
+		spacing float32
+		x       [2]geom.Pt
+		y       geom.Pt // of the second line
+	}{
+		{"left", Left, 0, 0, [2]geom.Pt{0, 0}, height},
+		{"center", Center, 0, 0, [2]geom.Pt{(long - short) / 2, 0}, height},
+		{"right", Right, 0, 0, [2]geom.Pt{long - short, 0}, height},
+		{"center in width", Center, 100, 0, [2]geom.Pt{(100 - short) / 2, (100 - long) / 2}, height},
+		{"right in width", Right, 100, 0, [2]geom.Pt{100 - short, 100 - long}, height},
+		{"line spacing", Left, 0, 1.5, [2]geom.Pt{0, 0}, 1.5 * height},
+	}
+	for _, tt := range tests {
+		s := &String{
+			Text:        "a\nlonger line",
+			Size:        size,
+			Font:        f,
+			Width:       tt.width,
+			Align:       tt.align,
+			LineSpacing: tt.spacing,
+		}
+		lines := s.Layout()
+		if len(lines) != 2 {
+			t.Fatalf("%s: %d lines, want 2", tt.name, len(lines))
+		}
+		for i, l := range lines {
+			if !near(l.X, tt.x[i]) {
+				t.Errorf("%s: line %d at x=%v, want %v", tt.name, i, l.X, tt.x[i])
+			}
+		}
+		if lines[0].Y != 0 || !near(lines[1].Y, tt.y) {
+			t.Errorf("%s: lines at y=%v and %v, want 0 and %v", tt.name, lines[0].Y, lines[1].Y, tt.y)
+		}
+	}
+}
+
+func near(a, b geom.Pt) bool {
+	return a-b < 0.01 && b-a < 0.01
+}
@@ -0,0 +1,99 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package text
+
+import (
+	"code.google.com/p/freetype-go/freetype/truetype"
+	"golang.org/x/mobile/geom"
+)
+
+// Metrics are the measurements of a line of text.
+//
+// Vertical measurements are relative to the baseline, with y
+// increasing downwards, as on the screen.
+type Metrics struct {
+	Advance geom.Pt        // distance to the start of the next text
+	Ascent  geom.Pt        // height of the font above the baseline
+	Descent geom.Pt        // depth of the font below the baseline
+	Ink     geom.Rectangle // bounds of the drawn glyphs, empty if none
+}
+
+// Measure measures text drawn as a single line by a String with the
+// given Font and Size. Newlines are measured as glyphs; use
+// String.Layout to break text into lines.
+func Measure(f *truetype.Font, size geom.Pt, text string) (Metrics, error) {
+	scale := floatToFix(size.Px())
+	b := f.Bounds(scale)
+	m := Metrics{
+		Ascent:  geom.Pt(fixToFloat(b.YMax) / geom.PixelsPerPt),
+		Descent: geom.Pt(fixToFloat(-b.YMin) / geom.PixelsPerPt),
+	}
+
+	buf := truetype.NewGlyphBuf()
+	var ink struct {
+		xmin, ymin, xmax, ymax float32 // pixels
+		empty                  bool
+	}
+	ink.empty = true
+	var err error
+	x := layoutGlyphs(f, scale, text, func(index truetype.Index, x float32) {
+		if err != nil {
+			return
+		}
+		if err = buf.Load(f, scale, index, truetype.NoHinting); err != nil {
+			return
+		}
+		gb := buf.B
+		if gb.XMin >= gb.XMax || gb.YMin >= gb.YMax {
+			return // nothing drawn, such as a space
+		}
+		x0, x1 := x+fixToFloat(gb.XMin), x+fixToFloat(gb.XMax)
+		y0, y1 := fixToFloat(-gb.YMax), fixToFloat(-gb.YMin)
+		if ink.empty || x0 < ink.xmin {
+			ink.xmin = x0
+		}
+		if ink.empty || y0 < ink.ymin {
+			ink.ymin = y0
+		}
+		if ink.empty || x1 > ink.xmax {
+			ink.xmax = x1
+		}
+		if ink.empty || y1 > ink.ymax {
+			ink.ymax = y1
+		}
+		ink.empty = false
+	})
+	if err != nil {
+		return Metrics{}, err
+	}
+	m.Advance = geom.Pt(x / geom.PixelsPerPt)
+	if !ink.empty {
+		m.Ink = geom.Rectangle{
+			Min: geom.Point{geom.Pt(ink.xmin / geom.PixelsPerPt), geom.Pt(ink.ymin / geom.PixelsPerPt)},
+			Max: geom.Point{geom.Pt(ink.xmax / geom.PixelsPerPt), geom.Pt(ink.ymax / geom.PixelsPerPt)},
+		}
+	}
+	return m, nil
+}
+
+// layoutGlyphs calls fn with each glyph of text and its x position in
+// pixels, applying kerning, and returns the advance width of text.
+// String.Arrange, Layout and Measure all place glyphs with it.
+func layoutGlyphs(f *truetype.Font, scale int32, text string, fn func(index truetype.Index, x float32)) float32 {
+	prev, hasPrev := truetype.Index(0), false
+	var x float32
+	for _, r := range text {
+		index := f.Index(r)
+		if hasPrev {
+			x += fixToFloat(f.Kerning(scale, prev, index))
+		}
+		if fn != nil {
+			fn(index, x)
+		}
+		x += fixToFloat(f.HMetric(scale, index).AdvanceWidth)
+		prev, hasPrev = index, true
+	}
+	return x
+}
@@ -0,0 +1,70 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package text
+
+import (
+	"testing"
+
+	"golang.org/x/mobile/geom"
+)
+
+func TestMeasure(t *testing.T) {
+	f := testFont(t)
+	const size = 12
+	for _, text := range []string{"", "a", "Gopher", "two words", "AVATAR"} {
+		m, err := Measure(f, size, text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := &String{Text: text, Size: size, Font: f}
+		if w := s.Layout()[0].Width; m.Advance != w {
+			t.Errorf("%q: advance %v, Layout width %v", text, m.Advance, w)
+		}
+		if m.Ascent <= 0 || m.Descent <= 0 || m.Ascent+m.Descent > 2*size {
+			t.Errorf("%q: ascent %v and descent %v, want positive and within the font height", text, m.Ascent, m.Descent)
+		}
+		if text == "" {
+			continue
+		}
+		ink := m.Ink
+		if ink.Min.X < -1 || ink.Max.X > m.Advance+1 || ink.Min.Y < -m.Ascent || ink.Max.Y > m.Descent {
+			t.Errorf("%q: ink %v outside advance %v, ascent %v and descent %v", text, ink, m.Advance, m.Ascent, m.Descent)
+		}
+		if ink.Max.X <= ink.Min.X || ink.Max.Y <= ink.Min.Y {
+			t.Errorf("%q: empty ink %v", text, ink)
+		}
+	}
+}
+
+func TestMeasureLines(t *testing.T) {
+	f := testFont(t)
+	const size = 12
+	s := &String{Text: "one\ntwo three\nfour", Size: size, Font: f}
+	var sum, want geom.Pt
+	for _, l := range s.Layout() {
+		sum += l.Width
+		want += advance(t, f, size, l.Text)
+	}
+	if !near(sum, want) {
+		t.Errorf("Layout widths add up to %v, Measure advances to %v", sum, want)
+	}
+	if got := advance(t, f, size, "one"); got >= advance(t, f, size, "one two") {
+		t.Errorf("advance of %q is %v, not less than that of a longer text", "one", got)
+	}
+}
+
+func TestMeasureSpaces(t *testing.T) {
+	f := testFont(t)
+	m, err := Measure(f, 12, "   ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Ink != (geom.Rectangle{}) {
+		t.Errorf("ink of spaces is %v, want empty", m.Ink)
+	}
+	if one := advance(t, f, 12, " "); !near(m.Advance, 3*one) {
+		t.Errorf("advance of 3 spaces is %v, want 3 × %v", m.Advance, one)
+	}
+}
@@ -5,7 +5,8 @@
 // Package text implements a sprite.Arranger that can lay out text.
 //
 // Glyphs are rendered into a shared, reused cache controlled by the Engine
-// implementation.
+// implementation. The cache holds the coverage of each glyph as white
+// with alpha, and is tinted to the color of a String when it is drawn.
 package text
 
 import (
@@ -13,7 +14,9 @@ import (
 	"fmt"
 	"image"
 	"image/color"
+	"image/draw"
 	"log"
+	"sort"
 
 	"code.google.com/p/freetype-go/freetype/raster"
 	"code.google.com/p/freetype-go/freetype/truetype"
@@ -21,27 +24,138 @@ import (
 	"golang.org/x/mobile/geom"
 	"golang.org/x/mobile/sprite"
 	"golang.org/x/mobile/sprite/clock"
+
+	"github.com/crawshaw/balloon/tint"
 )
 
-const colWidth = 128
+// Sheet dimensions, in pixels.
+const sheetWidth, sheetHeight = 1024, 512
+
+// sheetBytes is the memory used by a sheet: its texture, and the copy of
+// its pixels kept as the source for tinting.
+const sheetBytes = 2 * sheetWidth * sheetHeight * 4
+
+// CacheBudget is the most memory, in bytes, that the glyph cache of an
+// Engine uses. It counts the sheets of the cache and the tinted copies
+// of glyphs drawn from them. Sheets are added as they are needed until
+// the budget is reached, after which the least recently used glyphs are
+// evicted to make space. There is always at least one sheet.
+var CacheBudget = 4 * sheetBytes
 
 var cache = make(map[sprite.Engine]*glyphCache)
 
-// a sheet is made up of 128px columns, filled top-to-bottom, left-to-right.
-// TODO be cleverer: https://medium.com/@romainguy/androids-font-renderer-c368bbde87d9
+// a sheet is divided into horizontal shelves, each as tall as the glyph
+// it was made for. Glyphs are placed left to right on a shelf, and the
+// space of evicted glyphs is reused.
 type sheet struct {
-	s    sprite.Texture
-	x, y int // next empty slot
+	s       sprite.Texture
+	m       *image.RGBA // pixels of s, the source for tinting
+	shelves []*shelf    // top to bottom
 }
 
-type cacheEntry struct {
-	glyph        glyphKey
-	texture      sprite.SubTex
-	offset       image.Point
-	advanceWidth float32    // pixels
-	time         clock.Time // needed for rendering at time
+type shelf struct {
+	y, h int
+	x    int    // start of the unused space at the end of the shelf
+	free []span // evicted spaces before x, sorted by x
+}
+
+type span struct {
+	x, w int
+}
+
+// alloc reserves a w by h space on s.
+func (s *sheet) alloc(w, h int) (*shelf, image.Point, bool) {
+	// Prefer a shelf not much taller than the glyph.
+	for _, sh := range s.shelves {
+		if h <= sh.h && sh.h <= h+h/4+2 {
+			if x, ok := sh.alloc(w); ok {
+				return sh, image.Point{x, sh.y}, true
+			}
+		}
+	}
+	top := 0
+	if n := len(s.shelves); n > 0 {
+		top = s.shelves[n-1].y + s.shelves[n-1].h
+	}
+	if w <= sheetWidth && h <= sheetHeight-top {
+		sh := &shelf{y: top, h: h}
+		s.shelves = append(s.shelves, sh)
+		x, _ := sh.alloc(w)
+		return sh, image.Point{x, sh.y}, true
+	}
+	for _, sh := range s.shelves {
+		if h <= sh.h {
+			if x, ok := sh.alloc(w); ok {
+				return sh, image.Point{x, sh.y}, true
+			}
+		}
+	}
+	return nil, image.Point{}, false
+}
+
+// release frees r, which is on the shelf sh of s.
+func (s *sheet) release(sh *shelf, r image.Rectangle) {
+	sh.release(r.Min.X, r.Dx())
+
+	// Drop empty shelves from the bottom of the sheet.
+	for n := len(s.shelves); n > 0 && s.shelves[n-1].x == 0; n-- {
+		s.shelves = s.shelves[:n-1]
+	}
+}
 
-	next, prev *cacheEntry // linked-list, most recently used at front
+func (sh *shelf) alloc(w int) (int, bool) {
+	for i, f := range sh.free {
+		if w <= f.w {
+			if w == f.w {
+				sh.free = append(sh.free[:i], sh.free[i+1:]...)
+			} else {
+				sh.free[i] = span{f.x + w, f.w - w}
+			}
+			return f.x, true
+		}
+	}
+	if w > sheetWidth-sh.x {
+		return 0, false
+	}
+	x := sh.x
+	sh.x += w
+	return x, true
+}
+
+func (sh *shelf) release(x, w int) {
+	i := 0
+	for i < len(sh.free) && sh.free[i].x < x {
+		i++
+	}
+	sh.free = append(sh.free, span{})
+	copy(sh.free[i+1:], sh.free[i:])
+	sh.free[i] = span{x, w}
+
+	// Merge with the following and preceding spaces.
+	if i+1 < len(sh.free) && x+w == sh.free[i+1].x {
+		sh.free[i].w += sh.free[i+1].w
+		sh.free = append(sh.free[:i+1], sh.free[i+2:]...)
+	}
+	if i > 0 && sh.free[i-1].x+sh.free[i-1].w == x {
+		sh.free[i-1].w += sh.free[i].w
+		sh.free = append(sh.free[:i], sh.free[i+1:]...)
+		i--
+	}
+	// Return a space at the end to the unused space.
+	if f := sh.free[i]; f.x+f.w == sh.x {
+		sh.x = f.x
+		sh.free = sh.free[:i]
+	}
+}
+
+type cacheEntry struct {
+	glyph   glyphKey
+	sheet   *sheet // nil for an empty glyph
+	shelf   *shelf
+	texture sprite.SubTex
+	offset  image.Point
+	time    clock.Time              // needed for rendering at time
+	tinted  map[sprite.Texture]bool // tinted copies of texture
 }
 
 type glyphKey struct {
@@ -51,14 +165,19 @@ type glyphKey struct {
 }
 
 type glyphCache struct {
-	e sprite.Engine
-	s *sheet // TODO more sheets
-	r *raster.Rasterizer
-
-	glyphBuf   *truetype.GlyphBuf
-	cache      map[glyphKey]*cacheEntry
-	cacheFront *cacheEntry
-	scratch    *image.RGBA // TODO: *image.Alpha
+	e      sprite.Engine
+	sheets []*sheet
+	r      *raster.Rasterizer
+
+	glyphBuf *truetype.GlyphBuf
+	cache    map[glyphKey]*cacheEntry
+	scratch  *image.RGBA
+	tinted   int // bytes of tinted copies of glyphs
+}
+
+// size returns the memory used by c, in bytes.
+func (c *glyphCache) size() int {
+	return len(c.sheets)*sheetBytes + c.tinted
 }
 
 func (c *glyphCache) get(glyph glyphKey, t clock.Time) (*cacheEntry, error) {
@@ -69,91 +188,107 @@ func (c *glyphCache) get(glyph glyphKey, t clock.Time) (*cacheEntry, error) {
 			return nil, err
 		}
 		c.cache[glyph] = entry
-	} else {
-		entry.time = t
-
-		// remove from list
-		if entry.prev != nil {
-			entry.prev.next = entry.next
-		}
-		if entry.next != nil {
-			entry.next.prev = entry.prev
-		}
-	}
-
-	// put on front of list
-	entry.prev = nil
-	entry.next = c.cacheFront
-	if c.cacheFront != nil {
-		c.cacheFront.prev = entry
-		c.cacheFront = entry
 	}
+	entry.time = t
 	return entry, nil
 }
 
-func (c *glyphCache) findSpace(w, h int, t clock.Time) (image.Point, error) {
-	if w > colWidth {
-		return image.Point{}, fmt.Errorf("text: glyph larger than cache column width: %d", w)
+// findSpace finds a w by h space for a glyph, adding a sheet or evicting
+// glyphs not used at time t if the cache is full.
+func (c *glyphCache) findSpace(w, h int, t clock.Time) (*sheet, *shelf, image.Point, error) {
+	if w > sheetWidth || h > sheetHeight {
+		return nil, nil, image.Point{}, fmt.Errorf("text: glyph larger than cache sheet: w=%d, h=%d", w, h)
 	}
-	s := c.s
-	sw, sh := s.s.Bounds()
-	if h > sh-s.y {
-		s.x += colWidth
-		s.y = 0
+	for _, s := range c.sheets {
+		if sh, p, ok := s.alloc(w, h); ok {
+			return s, sh, p, nil
+		}
 	}
-	if s.x >= sw {
-		// out of space, clear out old glyphs
-		if err := c.clearHalf(t); err != nil {
-			return image.Point{}, err
+	if c.size()+sheetBytes <= CacheBudget {
+		s, err := c.newSheet()
+		if err != nil {
+			return nil, nil, image.Point{}, err
 		}
-		if h > sh-s.y {
-			s.x += colWidth
-			s.y = 0
+		sh, p, _ := s.alloc(w, h)
+		return s, sh, p, nil
+	}
+
+	// Evict glyphs, least recently used first, until there is space.
+	for _, e := range c.unused(t) {
+		delete(c.cache, e.glyph)
+		c.untint(e)
+		e.sheet.release(e.shelf, e.texture.R)
+		if sh, p, ok := e.sheet.alloc(w, h); ok {
+			return e.sheet, sh, p, nil
 		}
 	}
-	if w > sw-s.x || h > sh-s.y {
-		return image.Point{}, fmt.Errorf("text: no space for glyph w=%d, h=%d", w, h)
+	return nil, nil, image.Point{}, fmt.Errorf("text: glyph cache is full (%d items)", len(c.cache))
+}
+
+func (c *glyphCache) newSheet() (*sheet, error) {
+	m := image.NewRGBA(image.Rect(0, 0, sheetWidth, sheetHeight))
+	x, err := tint.LoadTexture(c.e, m)
+	if err != nil {
+		return nil, err
 	}
-	p := image.Point{s.x, s.y}
-	s.y += h
-	return p, nil
+	s := &sheet{s: x, m: m}
+	c.sheets = append(c.sheets, s)
+	return s, nil
 }
 
-func (c *glyphCache) clearHalf(t clock.Time) error {
-	e := c.cacheFront
-	for e.next != nil {
-		e = e.next
+// unused returns the drawn glyphs not used at time t, least recently
+// used first.
+func (c *glyphCache) unused(t clock.Time) []*cacheEntry {
+	var old []*cacheEntry
+	for _, e := range c.cache {
+		if e.time < t && e.sheet != nil {
+			old = append(old, e)
+		}
 	}
+	sort.Sort(byTime(old))
+	return old
+}
 
-	toDelete := len(c.cache) / 2
-	deleted := 0
-	for e != nil && toDelete > 0 {
-		if e.time < t {
-			delete(c.cache, e.glyph)
-			if e.next != nil {
-				e.next.prev = e.prev
-			}
-			if e.prev != nil {
-				e.prev.next = e.next
+// tint returns the glyph of entry multiplied by col. A new tinted copy
+// counts against CacheBudget, and if the cache is over budget, the
+// tinted copies of glyphs not used at time t are dropped.
+func (c *glyphCache) tint(entry *cacheEntry, col color.Color, t clock.Time) (sprite.SubTex, error) {
+	x, err := tint.SubTex(c.e, entry.texture, col)
+	if err != nil || x == entry.texture || entry.tinted[x.T] {
+		return x, err
+	}
+	if entry.tinted == nil {
+		entry.tinted = make(map[sprite.Texture]bool)
+	}
+	entry.tinted[x.T] = true
+	c.tinted += x.R.Dx() * x.R.Dy() * 4
+	if c.size() > CacheBudget {
+		for _, e := range c.unused(t) {
+			c.untint(e)
+			if c.size() <= CacheBudget {
+				break
 			}
-			deleted++
-			toDelete--
 		}
-		e = e.prev
-	}
-	if deleted == 0 {
-		return fmt.Errorf("text: glyph cache is full (%d items)", len(c.cache))
 	}
+	return x, nil
+}
 
-	// re-render cache
-	for e := c.cacheFront; e != nil; e = e.next {
-		if err := c.rasterize(e, e.time); err != nil {
-			return err
-		}
+// untint unloads the tinted copies of the glyph of e.
+func (c *glyphCache) untint(e *cacheEntry) {
+	if len(e.tinted) == 0 {
+		return
 	}
-	return nil
+	tint.Release(e.texture)
+	c.tinted -= len(e.tinted) * e.texture.R.Dx() * e.texture.R.Dy() * 4
+	e.tinted = nil
 }
 
+type byTime []*cacheEntry
+
+func (a byTime) Len() int           { return len(a) }
+func (a byTime) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+func (a byTime) Less(i, j int) bool { return a[i].time < a[j].time }
+
 func (c *glyphCache) rasterize(entry *cacheEntry, t clock.Time) error {
 	// Hinting is disabled. We can't pixel snap without knowing where the
 	// pixels are. As a bonus, we get a more space efficient glyph cache.
@@ -176,11 +311,15 @@ func (c *glyphCache) rasterize(entry *cacheEntry, t clock.Time) error {
 	}
 	w, h := xmax-xmin, ymax-ymin
 	entry.offset = image.Point{xmin, ymin}
-	p, err := c.findSpace(w, h, t)
+	if w == 0 || h == 0 {
+		// Nothing to draw, such as a space.
+		return nil
+	}
+	s, sh, p, err := c.findSpace(w, h, t)
 	if err != nil {
 		return err
 	}
-	entry.advanceWidth = fixToFloat(c.glyphBuf.AdvanceWidth)
+	entry.sheet, entry.shelf = s, sh
 
 	// A TrueType's glyph's nodes can have negative co-ordinates, but the
 	// rasterizer clips anything left of x=0 or above y=0. xmin and ymin
@@ -195,20 +334,22 @@ func (c *glyphCache) rasterize(entry *cacheEntry, t clock.Time) error {
 		drawContour(c.r, c.glyphBuf.Point[e0:e1], fx, fy)
 		e0 = e1
 	}
-	//a := c.scratch.SubImage(image.Rect(0, 0, w, h)).(*image.Alpha)
+	if sb := c.scratch.Bounds(); w > sb.Dx() || h > sb.Dy() {
+		c.scratch = image.NewRGBA(image.Rect(0, 0, max(w, sb.Dx()), max(h, sb.Dy())))
+	}
 	a := c.scratch.SubImage(image.Rect(0, 0, w, h)).(*image.RGBA)
 	for i := range a.Pix {
 		a.Pix[i] = 0
 	}
-	//c.r.Rasterize(raster.NewAlphaSrcPainter(a))
 	painter := raster.NewRGBAPainter(a)
-	painter.SetColor(color.Black)
+	painter.SetColor(color.White)
 	c.r.Rasterize(painter)
 	entry.texture = sprite.SubTex{
-		T: c.s.s,
+		T: s.s,
 		R: image.Rect(p.X, p.Y, p.X+w, p.Y+h),
 	}
-	c.s.s.Upload(entry.texture.R, a)
+	draw.Draw(s.m, entry.texture.R, a, image.Point{}, draw.Src)
+	s.s.Upload(entry.texture.R, a)
 	return nil
 }
 
@@ -217,24 +358,27 @@ func getCache(e sprite.Engine) (*glyphCache, error) {
 	if c != nil {
 		return c, nil
 	}
-	const w, h = 1024, 512
-	//x, err := e.LoadTexture(image.NewAlpha(image.Rect(0, 0, w, h)))
-	x, err := e.LoadTexture(image.NewRGBA(image.Rect(0, 0, w, h)))
-	if err != nil {
-		return nil, err
-	}
 	c = &glyphCache{
 		e:        e,
 		r:        raster.NewRasterizer(0, 0),
-		s:        &sheet{s: x},
 		glyphBuf: truetype.NewGlyphBuf(),
-		scratch:  image.NewRGBA(image.Rect(0, 0, colWidth, h)),
+		scratch:  image.NewRGBA(image.Rect(0, 0, 128, 128)),
 		cache:    make(map[glyphKey]*cacheEntry),
 	}
+	if _, err := c.newSheet(); err != nil {
+		return nil, err
+	}
 	cache[e] = c
 	return c, nil
 }
 
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
 // fixToFloat converts fixed width 26.6 numbers to float32.
 func fixToFloat(x int32) float32 {
 	return float32(x>>6) + float32(x&0x3f)/0x3f
@@ -293,12 +437,19 @@ func drawContour(r *raster.Rasterizer, ps []truetype.Point, dx, dy raster.Fix32)
 
 // String is a sprite.Arranger that draws a string.
 //
+// The text is laid out in lines by Layout, and the first baseline is at
+// the origin of the node.
+//
 // This arranger owns all child nodes, and rearranges them at will.
 type String struct {
 	Text  string
 	Size  geom.Pt
-	Color color.Color
+	Color color.Color // black if nil
 	Font  *truetype.Font
+
+	Width       geom.Pt // wrap lines to this width, if positive
+	Align       Align
+	LineSpacing float32 // multiple of the font height, 1 if zero
 }
 
 func (s *String) Arrange(e sprite.Engine, n *sprite.Node, t clock.Time) {
@@ -317,48 +468,50 @@ func (s *String) Arrange(e sprite.Engine, n *sprite.Node, t clock.Time) {
 	xmax := +int(b.XMax+63) >> 6
 	ymax := -int(b.YMin-63) >> 6
 	c.r.SetBounds(xmax-xmin, ymax-ymin)
+	col := s.Color
+	if col == nil {
+		col = color.Black
+	}
 
 	// TODO reuse nodes
 	n.FirstChild = nil
 	n.LastChild = nil
-	prev, hasPrev := truetype.Index(0), false
-	var x float32 // pixels
-	for _, rune := range s.Text {
-		index := s.Font.Index(rune)
-		if hasPrev {
-			x += fixToFloat(s.Font.Kerning(scale, prev, index))
-		}
-		entry, err := c.get(glyphKey{
-			index: index,
-			size:  s.Size,
-			font:  s.Font,
-		}, t)
-		if err != nil {
-			log.Fatal(err) // TODO return error
-		}
+	for _, line := range s.Layout() {
+		x0 := float32(line.X) * geom.PixelsPerPt // pixels
+		y := float32(line.Y) * geom.PixelsPerPt
+		layoutGlyphs(s.Font, scale, line.Text, func(index truetype.Index, x float32) {
+			entry, err := c.get(glyphKey{
+				index: index,
+				size:  s.Size,
+				font:  s.Font,
+			}, t)
+			if err != nil {
+				log.Fatal(err) // TODO return error
+			}
 
-		// Create a node to represent the glyph.
-		glyphNode := new(sprite.Node)
-		e.Register(glyphNode)
-		n.AppendChild(glyphNode)
-		var a f32.Affine
-		a.Identity()
-		a.Translate(
-			&a,
-			(x+float32(entry.offset.X))/geom.PixelsPerPt,
-			float32(entry.offset.Y)/geom.PixelsPerPt,
-		)
-		w, h := entry.texture.R.Dx(), entry.texture.R.Dy()
-		a.Scale(&a, float32(w)/geom.PixelsPerPt, float32(h)/geom.PixelsPerPt)
-		e.SetTransform(glyphNode, a)
-		subTex := entry.texture // copy
-		/*subTex.T = &sprite.AlphaTexutre{
-			T: subTex.T,
-			C: s.Color,
-		}*/
-		e.SetSubTex(glyphNode, subTex)
-
-		x += entry.advanceWidth
-		prev, hasPrev = index, true
+			// Create a node to represent the glyph.
+			glyphNode := new(sprite.Node)
+			e.Register(glyphNode)
+			n.AppendChild(glyphNode)
+			var a f32.Affine
+			a.Identity()
+			a.Translate(
+				&a,
+				(x0+x+float32(entry.offset.X))/geom.PixelsPerPt,
+				(y+float32(entry.offset.Y))/geom.PixelsPerPt,
+			)
+			w, h := entry.texture.R.Dx(), entry.texture.R.Dy()
+			a.Scale(&a, float32(w)/geom.PixelsPerPt, float32(h)/geom.PixelsPerPt)
+			e.SetTransform(glyphNode, a)
+			subTex := entry.texture // copy
+			if subTex.T != nil {
+				if x, err := c.tint(entry, col, t); err != nil {
+					log.Printf("text: %v", err) // draw the glyph untinted
+				} else {
+					subTex = x
+				}
+			}
+			e.SetSubTex(glyphNode, subTex)
+		})
 	}
 }
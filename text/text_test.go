@@ -0,0 +1,310 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package text
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"reflect"
+	"testing"
+
+	"code.google.com/p/freetype-go/freetype/truetype"
+	"golang.org/x/mobile/f32"
+	"golang.org/x/mobile/sprite"
+	"golang.org/x/mobile/sprite/clock"
+)
+
+func TestShelf(t *testing.T) {
+	type op struct {
+		release bool
+		x, w    int // x is the wanted position of an alloc
+	}
+	tests := []struct {
+		name string
+		ops  []op
+		x    int
+		free []span
+	}{
+		{
+			name: "alloc",
+			ops:  []op{{x: 0, w: 10}, {x: 10, w: 20}},
+			x:    30,
+		},
+		{
+			name: "release middle",
+			ops:  []op{{x: 0, w: 10}, {x: 10, w: 20}, {x: 30, w: 5}, {release: true, x: 10, w: 20}},
+			x:    35,
+			free: []span{{10, 20}},
+		},
+		{
+			name: "reuse",
+			ops: []op{
+				{x: 0, w: 10}, {x: 10, w: 20}, {x: 30, w: 5},
+				{release: true, x: 10, w: 20},
+				{x: 10, w: 15}, {x: 25, w: 5},
+			},
+			x: 35,
+		},
+		{
+			name: "reuse part",
+			ops: []op{
+				{x: 0, w: 10}, {x: 10, w: 20}, {x: 30, w: 5},
+				{release: true, x: 10, w: 20},
+				{x: 10, w: 15}, {x: 35, w: 6},
+			},
+			x:    41,
+			free: []span{{25, 5}},
+		},
+		{
+			name: "merge",
+			ops: []op{
+				{x: 0, w: 10}, {x: 10, w: 10}, {x: 20, w: 10}, {x: 30, w: 10}, {x: 40, w: 10},
+				{release: true, x: 10, w: 10},
+				{release: true, x: 30, w: 10},
+				{release: true, x: 20, w: 10},
+			},
+			x:    50,
+			free: []span{{10, 30}},
+		},
+		{
+			name: "merge trailing",
+			ops: []op{
+				{x: 0, w: 10}, {x: 10, w: 10}, {x: 20, w: 10},
+				{release: true, x: 10, w: 10},
+				{release: true, x: 20, w: 10},
+			},
+			x: 10,
+		},
+		{
+			name: "release all",
+			ops: []op{
+				{x: 0, w: 10}, {x: 10, w: 10},
+				{release: true, x: 0, w: 10},
+				{release: true, x: 10, w: 10},
+			},
+			x: 0,
+		},
+		{
+			name: "full",
+			ops:  []op{{x: 0, w: sheetWidth - 5}, {x: -1, w: 10}, {x: sheetWidth - 5, w: 5}},
+			x:    sheetWidth,
+		},
+	}
+	for _, tt := range tests {
+		sh := &shelf{h: 10}
+		for i, o := range tt.ops {
+			if o.release {
+				sh.release(o.x, o.w)
+				continue
+			}
+			x, ok := sh.alloc(o.w)
+			if o.x < 0 {
+				if ok {
+					t.Errorf("%s: op %d: alloc(%d) = %d, want failure", tt.name, i, o.w, x)
+				}
+				continue
+			}
+			if !ok || x != o.x {
+				t.Errorf("%s: op %d: alloc(%d) = %d, %t, want %d", tt.name, i, o.w, x, ok, o.x)
+			}
+		}
+		if len(sh.free) == 0 {
+			sh.free = nil
+		}
+		if sh.x != tt.x || !reflect.DeepEqual(sh.free, tt.free) {
+			t.Errorf("%s: x=%d free=%v, want x=%d free=%v", tt.name, sh.x, sh.free, tt.x, tt.free)
+		}
+	}
+}
+
+func TestSheet(t *testing.T) {
+	s := new(sheet)
+	alloc := func(w, h int, want image.Point) *shelf {
+		sh, p, ok := s.alloc(w, h)
+		if !ok || p != want {
+			t.Fatalf("alloc(%d, %d) = %v, %t, want %v", w, h, p, ok, want)
+		}
+		return sh
+	}
+	a := alloc(10, 20, image.Point{0, 0})
+	if b := alloc(10, 18, image.Point{10, 0}); b != a {
+		t.Error("a slightly shorter glyph did not share a shelf")
+	}
+	c := alloc(10, 8, image.Point{0, 20})
+	d := alloc(10, 8, image.Point{10, 20})
+	if c != d || len(s.shelves) != 2 {
+		t.Fatalf("got %d shelves, want 2", len(s.shelves))
+	}
+
+	// Releasing the bottom shelf drops it, once it is empty.
+	s.release(c, image.Rect(0, 20, 10, 28))
+	if len(s.shelves) != 2 {
+		t.Errorf("after releasing part of the bottom shelf, got %d shelves, want 2", len(s.shelves))
+	}
+	s.release(d, image.Rect(10, 20, 20, 28))
+	if len(s.shelves) != 1 {
+		t.Errorf("after emptying the bottom shelf, got %d shelves, want 1", len(s.shelves))
+	}
+	alloc(10, 8, image.Point{0, 20})
+
+	// A glyph too tall for the space left uses any shelf it fits.
+	for y := 28; y+100 <= sheetHeight; y += 100 {
+		alloc(sheetWidth, 100, image.Point{0, y})
+	}
+	alloc(sheetWidth, sheetHeight-428, image.Point{0, 428})
+	alloc(10, 12, image.Point{20, 0})
+	if _, _, ok := s.alloc(10, sheetHeight); ok {
+		t.Error("alloc of a glyph taller than the free space succeeded")
+	}
+}
+
+type testEngine struct {
+	loaded int // textures loaded and not unloaded
+}
+
+func (e *testEngine) Register(n *sprite.Node)                   {}
+func (e *testEngine) Unregister(n *sprite.Node)                 {}
+func (e *testEngine) SetSubTex(n *sprite.Node, x sprite.SubTex) {}
+func (e *testEngine) SetTransform(n *sprite.Node, m f32.Affine) {}
+func (e *testEngine) Render(scene *sprite.Node, t clock.Time)   {}
+func (e *testEngine) LoadTexture(m image.Image) (sprite.Texture, error) {
+	e.loaded++
+	return &testTexture{e: e, m: m}, nil
+}
+
+type testTexture struct {
+	e *testEngine
+	m image.Image
+}
+
+func (t *testTexture) Bounds() (w, h int)                         { return t.m.Bounds().Dx(), t.m.Bounds().Dy() }
+func (t *testTexture) Download(r image.Rectangle, dst draw.Image) {}
+func (t *testTexture) Upload(r image.Rectangle, src image.Image)  {}
+func (t *testTexture) Unload()                                    { t.e.loaded-- }
+
+// add puts a w by h glyph with the given index in c at time t, as
+// rasterize does.
+func (c *glyphCache) add(index, w, h int, t clock.Time) (*cacheEntry, error) {
+	s, sh, p, err := c.findSpace(w, h, t)
+	if err != nil {
+		return nil, err
+	}
+	e := &cacheEntry{
+		glyph:   glyphKey{index: truetype.Index(index)},
+		sheet:   s,
+		shelf:   sh,
+		texture: sprite.SubTex{T: s.s, R: image.Rect(p.X, p.Y, p.X+w, p.Y+h)},
+		time:    t,
+	}
+	c.cache[e.glyph] = e
+	return e, nil
+}
+
+// newTestCache returns a glyphCache with one sheet. The caller sets and
+// restores CacheBudget.
+func newTestCache(t *testing.T) *glyphCache {
+	c := &glyphCache{
+		e:     new(testEngine),
+		cache: make(map[glyphKey]*cacheEntry),
+	}
+	if _, err := c.newSheet(); err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestCacheGrowAndEvict(t *testing.T) {
+	defer func(b int) { CacheBudget = b }(CacheBudget)
+	CacheBudget = 2 * sheetBytes
+	c := newTestCache(t)
+	add := func(index int, t0 clock.Time) {
+		if _, err := c.add(index, sheetWidth, sheetHeight, t0); err != nil {
+			t.Fatalf("glyph %d: %v", index, err)
+		}
+	}
+	has := func(indexes ...int) {
+		if len(c.cache) != len(indexes) {
+			t.Errorf("%d glyphs cached, want %d", len(c.cache), len(indexes))
+		}
+		for _, i := range indexes {
+			if c.cache[glyphKey{index: truetype.Index(i)}] == nil {
+				t.Errorf("glyph %d is not cached", i)
+			}
+		}
+	}
+
+	add(0, 1)
+	add(1, 2)
+	if len(c.sheets) != 2 {
+		t.Fatalf("%d sheets, want 2", len(c.sheets))
+	}
+	has(0, 1)
+
+	// The budget is reached, so the least recently used glyph goes.
+	add(2, 3)
+	has(1, 2)
+	add(3, 3)
+	has(2, 3)
+	if len(c.sheets) != 2 {
+		t.Errorf("%d sheets, want 2", len(c.sheets))
+	}
+
+	// Glyphs used at the current time are not evicted.
+	if _, err := c.add(4, sheetWidth, sheetHeight, 3); err == nil {
+		t.Error("add succeeded with every glyph in use")
+	}
+}
+
+func TestCacheTintBudget(t *testing.T) {
+	const w, h = 10, 10
+	const glyphBytes = w * h * 4
+	defer func(b int) { CacheBudget = b }(CacheBudget)
+	CacheBudget = sheetBytes + 2*glyphBytes
+	c := newTestCache(t)
+	e := c.e.(*testEngine)
+
+	a, err := c.add(0, w, h, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := c.add(1, w, h, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	red := color.NRGBA{0xff, 0, 0, 0xff}
+	blue := color.NRGBA{0, 0, 0xff, 0xff}
+	if _, err := c.tint(a, red, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.tint(b, red, 2); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.tint(b, red, 2); err != nil {
+		t.Fatal(err)
+	}
+	if c.tinted != 2*glyphBytes || e.loaded != 3 {
+		t.Fatalf("tinted %d bytes in %d textures, want %d bytes in 3", c.tinted, e.loaded, 2*glyphBytes)
+	}
+
+	// A third copy is over budget, so the copy of the unused glyph goes.
+	if _, err := c.tint(b, blue, 2); err != nil {
+		t.Fatal(err)
+	}
+	if c.tinted != 2*glyphBytes || len(a.tinted) != 0 || len(b.tinted) != 2 {
+		t.Errorf("tinted %d bytes, %d and %d copies, want %d bytes, 0 and 2 copies", c.tinted, len(a.tinted), len(b.tinted), 2*glyphBytes)
+	}
+	if e.loaded != 3 {
+		t.Errorf("%d textures loaded, want 3", e.loaded)
+	}
+
+	// Evicting a glyph unloads its tinted copies.
+	if _, err := c.add(2, sheetWidth, sheetHeight, 3); err != nil {
+		t.Fatal(err)
+	}
+	if c.tinted != 0 || e.loaded != 1 {
+		t.Errorf("after eviction, tinted %d bytes in %d textures, want 0 bytes in 1", c.tinted, e.loaded)
+	}
+}
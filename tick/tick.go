@@ -0,0 +1,116 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package tick provides sources of game time.
+//
+// Game time is a clock.Time, counted in ticks of 1/60 of a second.
+// Each frame asks its Source for the frame's time, and everything else,
+// such as touch handling, uses the time of the current frame.
+package tick
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/mobile/sprite/clock"
+)
+
+// Source is a source of game time.
+type Source interface {
+	// Frame advances to the time of a new frame and returns it.
+	Frame() clock.Time
+
+	// Now returns the time of the current frame.
+	Now() clock.Time
+}
+
+// Real is wall-clock time, at an adjustable speed.
+type Real struct {
+	mu    sync.Mutex
+	wall  time.Time  // wall time of base
+	base  float64    // ticks at wall
+	speed float64    // ticks per 1/60s of wall time
+	now   clock.Time // time of the current frame
+}
+
+// NewReal returns a Real source starting at time 0 at normal speed.
+func NewReal() *Real {
+	return &Real{
+		wall:  time.Now(),
+		speed: 1,
+	}
+}
+
+func (r *Real) ticks(now time.Time) float64 {
+	return r.base + r.speed*60*now.Sub(r.wall).Seconds()
+}
+
+func (r *Real) Frame() clock.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.now = clock.Time(r.ticks(time.Now()))
+	return r.now
+}
+
+func (r *Real) Now() clock.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.now
+}
+
+// SetSpeed changes how fast game time passes relative to wall time.
+// A speed of 2 fast-forwards, 0.5 is slow motion and 0 pauses.
+func (r *Real) SetSpeed(speed float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	r.base = r.ticks(now)
+	r.wall = now
+	r.speed = speed
+}
+
+// Speed returns the speed set by SetSpeed.
+func (r *Real) Speed() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.speed
+}
+
+// Fixed advances by a fixed number of ticks each frame, however long
+// the frame takes to draw.
+type Fixed struct {
+	Step clock.Time // ticks per frame, 1 if zero
+
+	now     clock.Time
+	started bool
+}
+
+// Frame returns 0 for the first frame, then advances by Step.
+func (f *Fixed) Frame() clock.Time {
+	if !f.started {
+		f.started = true
+		return f.now
+	}
+	step := f.Step
+	if step == 0 {
+		step = 1
+	}
+	f.now += step
+	return f.now
+}
+
+func (f *Fixed) Now() clock.Time { return f.now }
+
+// Manual is time that only changes when it is set.
+type Manual struct {
+	T clock.Time
+}
+
+func (m *Manual) Frame() clock.Time { return m.T }
+func (m *Manual) Now() clock.Time   { return m.T }
+
+// Advance moves the time forward by d ticks.
+func (m *Manual) Advance(d clock.Time) {
+	m.T += d
+}
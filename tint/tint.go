@@ -0,0 +1,156 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package tint multiplies sprite textures by a color.
+//
+// The sprite engines draw a SubTex as it is, so a tinted SubTex is a new
+// texture made from the source pixels. The source image of a texture must
+// be known to this package, by loading the texture with LoadTexture.
+// A texture whose pixels change after it is loaded must keep its source
+// image up to date, and call Release for each changed SubTex.
+//
+// Tinted textures are cached, up to MaxCached of them. Colors are
+// quantized so that a fade or a color tween reuses a small number of
+// textures.
+package tint
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"golang.org/x/mobile/sprite"
+)
+
+// The number of distinct values of each channel in tinted textures.
+const (
+	colorLevels = 16
+	alphaLevels = 16
+)
+
+// MaxCached is the most tinted textures kept loaded. When there are
+// more, the least recently used are unloaded, so it should be more than
+// the number of tinted textures drawn in one frame.
+var MaxCached = 64
+
+var (
+	sources = make(map[sprite.Texture]image.Image)
+	cache   = make(map[key]*entry)
+	colors  = make(map[sprite.SubTex][]color.NRGBA) // cached colors of each SubTex
+	uses    int                                     // count of cache lookups
+)
+
+type key struct {
+	x sprite.SubTex
+	c color.NRGBA
+}
+
+type entry struct {
+	x    sprite.SubTex
+	used int // value of uses when last used
+}
+
+// LoadTexture loads m into e, and remembers m as the source of the texture.
+func LoadTexture(e sprite.Engine, m image.Image) (sprite.Texture, error) {
+	t, err := e.LoadTexture(m)
+	if err != nil {
+		return nil, err
+	}
+	sources[t] = m
+	return t, nil
+}
+
+// SubTex returns x multiplied by c.
+func SubTex(e sprite.Engine, x sprite.SubTex, c color.Color) (sprite.SubTex, error) {
+	k := key{x: x, c: quantize(c)}
+	if k.c == (color.NRGBA{0xff, 0xff, 0xff, 0xff}) {
+		return x, nil
+	}
+	uses++
+	if y, ok := cache[k]; ok {
+		y.used = uses
+		return y.x, nil
+	}
+	src := sources[x.T]
+	if src == nil {
+		return sprite.SubTex{}, fmt.Errorf("tint: no source image for texture %v", x.T)
+	}
+
+	cr, cg, cb, ca := k.c.RGBA()
+	m := image.NewRGBA(image.Rect(0, 0, x.R.Dx(), x.R.Dy()))
+	for y := 0; y < x.R.Dy(); y++ {
+		for x0 := 0; x0 < x.R.Dx(); x0++ {
+			r, g, b, a := src.At(x.R.Min.X+x0, x.R.Min.Y+y).RGBA()
+			m.SetRGBA64(x0, y, color.RGBA64{
+				R: uint16(r * cr / 0xffff),
+				G: uint16(g * cg / 0xffff),
+				B: uint16(b * cb / 0xffff),
+				A: uint16(a * ca / 0xffff),
+			})
+		}
+	}
+	t, err := e.LoadTexture(m)
+	if err != nil {
+		return sprite.SubTex{}, err
+	}
+	y := sprite.SubTex{T: t, R: m.Bounds()}
+	cache[k] = &entry{x: y, used: uses}
+	colors[x] = append(colors[x], k.c)
+	for len(cache) > MaxCached {
+		evict()
+	}
+	return y, nil
+}
+
+// evict unloads the least recently used tinted texture.
+func evict() {
+	var lru key
+	var e *entry
+	for k, y := range cache {
+		if e == nil || y.used < e.used {
+			lru, e = k, y
+		}
+	}
+	unload(lru)
+}
+
+// unload unloads the tinted texture for k.
+func unload(k key) {
+	cache[k].x.T.Unload()
+	delete(cache, k)
+	cs := colors[k.x]
+	for i, c := range cs {
+		if c == k.c {
+			cs = append(cs[:i], cs[i+1:]...)
+			break
+		}
+	}
+	if len(cs) == 0 {
+		delete(colors, k.x)
+	} else {
+		colors[k.x] = cs
+	}
+}
+
+// Release unloads the tinted copies of x, for when the pixels of x change.
+func Release(x sprite.SubTex) {
+	for _, c := range append([]color.NRGBA(nil), colors[x]...) {
+		unload(key{x: x, c: c})
+	}
+}
+
+func quantize(c color.Color) color.NRGBA {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	n.R = level(n.R, colorLevels)
+	n.G = level(n.G, colorLevels)
+	n.B = level(n.B, colorLevels)
+	n.A = level(n.A, alphaLevels)
+	return n
+}
+
+// level rounds v to the nearest of n evenly spaced values.
+func level(v uint8, n int) uint8 {
+	step := 0xff / (n - 1)
+	return uint8((int(v) + step/2) / step * step)
+}
@@ -0,0 +1,97 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tint
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	"golang.org/x/mobile/f32"
+	"golang.org/x/mobile/sprite"
+	"golang.org/x/mobile/sprite/clock"
+)
+
+type testEngine struct {
+	loaded int // textures loaded and not unloaded
+}
+
+func (e *testEngine) Register(n *sprite.Node)                   {}
+func (e *testEngine) Unregister(n *sprite.Node)                 {}
+func (e *testEngine) SetSubTex(n *sprite.Node, x sprite.SubTex) {}
+func (e *testEngine) SetTransform(n *sprite.Node, m f32.Affine) {}
+func (e *testEngine) Render(scene *sprite.Node, t clock.Time)   {}
+func (e *testEngine) LoadTexture(m image.Image) (sprite.Texture, error) {
+	e.loaded++
+	return &testTexture{e: e, m: m}, nil
+}
+
+type testTexture struct {
+	e *testEngine
+	m image.Image
+}
+
+func (t *testTexture) Bounds() (w, h int)                         { return t.m.Bounds().Dx(), t.m.Bounds().Dy() }
+func (t *testTexture) Download(r image.Rectangle, dst draw.Image) {}
+func (t *testTexture) Upload(r image.Rectangle, src image.Image)  {}
+func (t *testTexture) Unload()                                    { t.e.loaded-- }
+
+func TestTweenIsBounded(t *testing.T) {
+	e := new(testEngine)
+	tex, err := LoadTexture(e, image.NewRGBA(image.Rect(0, 0, 4, 4)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	x := sprite.SubTex{T: tex, R: image.Rect(0, 0, 4, 4)}
+
+	// Tween from white to red.
+	for i := 0; i <= 255; i++ {
+		c := color.NRGBA{0xff, uint8(255 - i), uint8(255 - i), 0xff}
+		if _, err := SubTex(e, x, c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := e.loaded - 1; got > colorLevels {
+		t.Errorf("%d tinted textures loaded, want at most %d", got, colorLevels)
+	}
+}
+
+func TestMaxCached(t *testing.T) {
+	defer func(n int) { MaxCached = n }(MaxCached)
+	MaxCached = 4
+
+	e := new(testEngine)
+	tex, err := LoadTexture(e, image.NewRGBA(image.Rect(0, 0, 4, 4)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	x := sprite.SubTex{T: tex, R: image.Rect(0, 0, 4, 4)}
+	for i := 0; i < 10; i++ {
+		c := color.NRGBA{uint8(i * 17), 0, 0, 0xff}
+		if _, err := SubTex(e, x, c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := e.loaded - 1; got != MaxCached {
+		t.Errorf("%d tinted textures loaded, want %d", got, MaxCached)
+	}
+	Release(x)
+	if got := e.loaded - 1; got != 0 {
+		t.Errorf("%d tinted textures loaded after Release, want 0", got)
+	}
+}
+
+func TestNoSource(t *testing.T) {
+	e := new(testEngine)
+	tex, err := e.LoadTexture(image.NewRGBA(image.Rect(0, 0, 4, 4)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	x := sprite.SubTex{T: tex, R: image.Rect(0, 0, 4, 4)}
+	if _, err := SubTex(e, x, color.Black); err == nil {
+		t.Error("tinting a texture without a source succeeded")
+	}
+}